@@ -0,0 +1,167 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// orphanRequeueInterval is how long to wait before checking again whether the Application of an orphaned Component
+// exists. Components are also requeued as soon as their Application gets created.
+const orphanRequeueInterval = 5 * time.Minute
+
+// Reasons of the events recorded on Components by the ApplicationOwnershipReconciler
+const (
+	adoptionPendingReason = "AdoptionPending"
+	adoptionFailedReason  = "AdoptionFailed"
+)
+
+// ApplicationOwnershipReconciler sets the Application named in spec.application as the controller owner of a Component
+type ApplicationOwnershipReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder record.EventRecorder
+
+	// pendingAdoptions maps the Components waiting for their Application to be created to the name of the Application,
+	// so that the pending adoption is only recorded once rather than on every requeue
+	pendingAdoptions sync.Map
+}
+
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=components,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile adopts the Component into its Application by setting a controller owner reference on it.
+// If the Application does not exist yet, the Component is requeued.
+// Adoption failures are recorded as events on the Component, so that they show up when describing it, as well as the
+// adoption becoming pending on an Application that doesn't exist.
+func (r *ApplicationOwnershipReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("controllerKind", "Component").WithValues("name", req.Name).WithValues("namespace", req.Namespace)
+
+	var component appstudiov1alpha1.Component
+	err := r.Get(ctx, req.NamespacedName, &component)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			r.pendingAdoptions.Delete(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !component.DeletionTimestamp.IsZero() || component.Spec.Application == "" {
+		r.pendingAdoptions.Delete(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	var application appstudiov1alpha1.Application
+	err = r.Get(ctx, types.NamespacedName{Namespace: component.Namespace, Name: component.Spec.Application}, &application)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			log.Info(fmt.Sprintf("Application %s not found, requeueing the Component until it is created", component.Spec.Application))
+			if previous, pending := r.pendingAdoptions.Swap(req.NamespacedName, component.Spec.Application); !pending || previous != component.Spec.Application {
+				r.Recorder.Eventf(&component, corev1.EventTypeNormal, adoptionPendingReason, "Application %s does not exist yet, the Component will be adopted once it is created", component.Spec.Application)
+			}
+			return ctrl.Result{RequeueAfter: orphanRequeueInterval}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	r.pendingAdoptions.Delete(req.NamespacedName)
+
+	if !application.DeletionTimestamp.IsZero() {
+		// Don't adopt the Component into an Application that is going away
+		return ctrl.Result{}, nil
+	}
+
+	if owner := metav1.GetControllerOf(&component); owner != nil && owner.UID == application.UID {
+		return ctrl.Result{}, nil
+	}
+
+	err = controllerutil.SetControllerReference(&application, &component, r.Scheme)
+	if err != nil {
+		// The Component is already controlled by another resource, leave it as is
+		log.Error(err, "unable to set the Application as the controller owner of the Component")
+		r.Recorder.Eventf(&component, corev1.EventTypeWarning, adoptionFailedReason, "Unable to set Application %s as the controller owner: %v", application.Name, err)
+		return ctrl.Result{}, nil
+	}
+
+	err = r.Update(ctx, &component)
+	if err != nil {
+		log.Error(err, "error setting owner-references")
+		r.Recorder.Eventf(&component, corev1.EventTypeWarning, adoptionFailedReason, "Unable to set Application %s as the controller owner: %v", application.Name, err)
+		return ctrl.Result{}, err
+	}
+
+	log.Info(fmt.Sprintf("Finished reconcile loop for %v", req.NamespacedName))
+	return ctrl.Result{}, nil
+}
+
+// componentsForApplication returns a reconcile request for every Component in the Application's namespace
+// that names the Application in its spec.application field
+func (r *ApplicationOwnershipReconciler) componentsForApplication(ctx context.Context, obj client.Object) []reconcile.Request {
+	var componentList appstudiov1alpha1.ComponentList
+	err := r.List(ctx, &componentList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		r.Log.Error(err, "unable to list the Components of the Application", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, component := range componentList.Items {
+		if component.Spec.Application == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: component.Namespace, Name: component.Name}})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ApplicationOwnershipReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	// Only newly created Applications can adopt orphaned Components
+	applicationCreated := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool { return false },
+		DeleteFunc: func(e event.DeleteEvent) bool { return false },
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("applicationownership").
+		For(&appstudiov1alpha1.Component{}).
+		Watches(&appstudiov1alpha1.Application{},
+			handler.EnqueueRequestsFromMapFunc(r.componentsForApplication),
+			builder.WithPredicates(applicationCreated)).
+		Complete(r)
+}
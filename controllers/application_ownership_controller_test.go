@@ -0,0 +1,207 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestApplicationOwnershipReconcile(t *testing.T) {
+	tests := []struct {
+		name            string
+		createApp       bool
+		existingOwners  []v1.OwnerReference
+		wantOwner       bool
+		wantRequeue     bool
+		wantOwnerRefLen int
+		wantEvents      []string
+	}{
+		{
+			name:            "component is adopted by its application",
+			createApp:       true,
+			wantOwner:       true,
+			wantOwnerRefLen: 1,
+		},
+		{
+			name:        "orphaned component is requeued",
+			wantRequeue: true,
+			wantEvents:  []string{"Normal AdoptionPending Application application1 does not exist yet, the Component will be adopted once it is created"},
+		},
+		{
+			name:      "non-controller owner reference to the application is upgraded",
+			createApp: true,
+			existingOwners: []v1.OwnerReference{
+				{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Application",
+					Name:       "application1",
+				},
+			},
+			wantOwner:       true,
+			wantOwnerRefLen: 1,
+		},
+		{
+			name:      "component controlled by another resource is left alone",
+			createApp: true,
+			existingOwners: []v1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+					Name:       "other-owner",
+					UID:        "other-uid",
+					Controller: boolPtr(true),
+				},
+			},
+			wantOwnerRefLen: 1,
+			wantEvents:      []string{"Warning AdoptionFailed Unable to set Application application1 as the controller owner: Object default/component1 is already owned by another ConfigMap controller other-owner"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fakeClient := newFakeClient(t)
+			app := &appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "application1",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "application1",
+				},
+			}
+			if test.createApp {
+				err := fakeClient.Create(context.Background(), app)
+				require.NoError(t, err)
+			}
+
+			component := newComponent("component1", nil, nil)
+			component.OwnerReferences = test.existingOwners
+			err := fakeClient.Create(context.Background(), &component)
+			require.NoError(t, err)
+
+			recorder := record.NewFakeRecorder(10)
+			r := &ApplicationOwnershipReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Log:      ctrl.Log.WithName("controllers").WithName("ApplicationOwnership"),
+				Recorder: recorder,
+			}
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "component1"}})
+			require.NoError(t, err)
+			assert.Equal(t, test.wantRequeue, result.RequeueAfter > 0)
+
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			assert.Equal(t, test.wantEvents, events)
+
+			updatedComp := &appstudiov1alpha1.Component{}
+			err = fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "component1"}, updatedComp)
+			require.NoError(t, err)
+			assert.Len(t, updatedComp.OwnerReferences, test.wantOwnerRefLen)
+
+			owner := v1.GetControllerOf(updatedComp)
+			if test.wantOwner {
+				require.NotNil(t, owner)
+				assert.Equal(t, "Application", owner.Kind)
+				assert.Equal(t, "application1", owner.Name)
+				assert.Equal(t, app.UID, owner.UID)
+				require.NotNil(t, owner.BlockOwnerDeletion)
+				assert.True(t, *owner.BlockOwnerDeletion)
+			} else if owner != nil {
+				assert.NotEqual(t, "Application", owner.Kind)
+			}
+		})
+	}
+}
+
+func TestApplicationOwnershipReconcilePendingEvent(t *testing.T) {
+	fakeClient := newFakeClient(t)
+	component := newComponent("component1", nil, nil)
+	err := fakeClient.Create(context.Background(), &component)
+	require.NoError(t, err)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &ApplicationOwnershipReconciler{
+		Client:   fakeClient,
+		Scheme:   fakeClient.Scheme(),
+		Log:      ctrl.Log.WithName("controllers").WithName("ApplicationOwnership"),
+		Recorder: recorder,
+	}
+	reconcile := func() {
+		result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "component1"}})
+		require.NoError(t, err)
+		assert.True(t, result.RequeueAfter > 0)
+	}
+
+	// The pending adoption is only recorded on the first requeue
+	reconcile()
+	reconcile()
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Normal AdoptionPending Application application1 does not exist yet, the Component will be adopted once it is created", <-recorder.Events)
+
+	// It is recorded again once the Component names another Application
+	component.Spec.Application = "application2"
+	err = fakeClient.Update(context.Background(), &component)
+	require.NoError(t, err)
+	reconcile()
+	reconcile()
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Normal AdoptionPending Application application2 does not exist yet, the Component will be adopted once it is created", <-recorder.Events)
+}
+
+func TestComponentsForApplication(t *testing.T) {
+	fakeClient := newFakeClient(t)
+	component1 := newComponent("component1", nil, nil)
+	component2 := newComponent("component2", nil, nil)
+	component3 := newComponent("component3", nil, nil)
+	component3.Spec.Application = "application2"
+	for _, comp := range []*appstudiov1alpha1.Component{&component1, &component2, &component3} {
+		err := fakeClient.Create(context.Background(), comp)
+		require.NoError(t, err)
+	}
+
+	r := &ApplicationOwnershipReconciler{
+		Client: fakeClient,
+		Scheme: fakeClient.Scheme(),
+	}
+	app := &appstudiov1alpha1.Application{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "application1",
+			Namespace: "default",
+		},
+	}
+
+	var names []string
+	for _, req := range r.componentsForApplication(context.Background(), app) {
+		names = append(names, req.Name)
+	}
+	assert.ElementsMatch(t, []string{"component1", "component2"}, names)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
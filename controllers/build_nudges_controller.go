@@ -0,0 +1,184 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// BuildNudgesReconciler reconciles the status.build-nudged-by field of Components, based on the
+// spec.build-nudges-ref fields of the other Components in the same namespace
+type BuildNudgesReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder record.EventRecorder
+}
+
+// buildNudgesCleanupFailedReason is the reason of the event recorded on a Component whose status.build-nudged-by
+// field could not be updated
+const buildNudgesCleanupFailedReason = "BuildNudgesCleanupFailed"
+
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=components,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=components/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile recomputes the list of Components nudging the given Component and updates its status.build-nudged-by
+// field if it has drifted from the build-nudges-ref fields currently set in the namespace
+func (r *BuildNudgesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("controllerKind", "Component").WithValues("name", req.Name).WithValues("namespace", req.Namespace)
+
+	var component appstudiov1alpha1.Component
+	err := r.Get(ctx, req.NamespacedName, &component)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			// The Component was deleted, the nudged Components get reconciled separately
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !component.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	var componentList appstudiov1alpha1.ComponentList
+	err = r.List(ctx, &componentList, client.InNamespace(req.Namespace))
+	if err != nil {
+		log.Error(err, "unable to list the Components in the namespace")
+		return ctrl.Result{}, err
+	}
+
+	buildNudgedBy := nudgingComponentNames(component.Name, componentList.Items)
+	if stringSetsEqual(buildNudgedBy, component.Status.BuildNudgedBy) {
+		return ctrl.Result{}, nil
+	}
+
+	component.Status.BuildNudgedBy = buildNudgedBy
+	err = r.Client.Status().Update(ctx, &component)
+	if err != nil {
+		log.Error(err, "error setting build-nudged-by in status")
+		// Conflicts are expected when the Component changed concurrently, and are solved by the retry
+		if !k8sErrors.IsConflict(err) {
+			r.Recorder.Eventf(&component, corev1.EventTypeWarning, buildNudgesCleanupFailedReason, "Unable to update status.build-nudged-by to %v: %v", buildNudgedBy, err)
+		}
+		return ctrl.Result{}, err
+	}
+
+	log.Info(fmt.Sprintf("Finished reconcile loop for %v", req.NamespacedName))
+	return ctrl.Result{}, nil
+}
+
+// nudgingComponentNames returns the sorted names of the Components that list componentName in their build-nudges-ref.
+// Components that are being deleted are ignored.
+func nudgingComponentNames(componentName string, components []appstudiov1alpha1.Component) []string {
+	var nudgedBy []string
+	for _, comp := range components {
+		if comp.Name == componentName || !comp.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if util.StrInList(componentName, comp.Spec.BuildNudgesRef) && !util.StrInList(comp.Name, nudgedBy) {
+			nudgedBy = append(nudgedBy, comp.Name)
+		}
+	}
+	sort.Strings(nudgedBy)
+	return nudgedBy
+}
+
+// stringSetsEqual returns true if both lists contain the same strings, regardless of their order
+func stringSetsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, val := range a {
+		if !util.StrInList(val, b) {
+			return false
+		}
+	}
+	for _, val := range b {
+		if !util.StrInList(val, a) {
+			return false
+		}
+	}
+	return true
+}
+
+// nudgedComponentsHandler enqueues the Components referenced in the build-nudges-ref of a Component that was
+// created, deleted, or had its build-nudges-ref changed. On update, the Components referenced before and after the
+// change are enqueued, so that Components that are no longer nudged get their status cleaned up.
+func nudgedComponentsHandler() handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			enqueueNudgedComponents(q, e.Object)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			oldComp, ok := e.ObjectOld.(*appstudiov1alpha1.Component)
+			if !ok {
+				return
+			}
+			newComp, ok := e.ObjectNew.(*appstudiov1alpha1.Component)
+			if !ok {
+				return
+			}
+			if stringSetsEqual(oldComp.Spec.BuildNudgesRef, newComp.Spec.BuildNudgesRef) && oldComp.DeletionTimestamp.Equal(newComp.DeletionTimestamp) {
+				return
+			}
+			enqueueNudgedComponents(q, oldComp)
+			enqueueNudgedComponents(q, newComp)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			enqueueNudgedComponents(q, e.Object)
+		},
+	}
+}
+
+// enqueueNudgedComponents adds a reconcile request for every Component listed in the build-nudges-ref of obj
+func enqueueNudgedComponents(q workqueue.RateLimitingInterface, obj client.Object) {
+	component, ok := obj.(*appstudiov1alpha1.Component)
+	if !ok {
+		return
+	}
+	for _, nudgedComponentName := range component.Spec.BuildNudgesRef {
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: component.Namespace, Name: nudgedComponentName}})
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BuildNudgesReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("buildnudges").
+		For(&appstudiov1alpha1.Component{}).
+		Watches(&appstudiov1alpha1.Component{}, nudgedComponentsHandler()).
+		Complete(r)
+}
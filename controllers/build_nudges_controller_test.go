@@ -0,0 +1,271 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestBuildNudgesReconcile(t *testing.T) {
+	tests := []struct {
+		name          string
+		componentName string
+		components    []appstudiov1alpha1.Component
+		wantNudgedBy  []string
+	}{
+		{
+			name:          "nudged component gets its status set",
+			componentName: "component2",
+			components: []appstudiov1alpha1.Component{
+				newComponent("component1", []string{"component2"}, nil),
+				newComponent("component2", nil, nil),
+			},
+			wantNudgedBy: []string{"component1"},
+		},
+		{
+			name:          "multiple nudging components are sorted",
+			componentName: "component3",
+			components: []appstudiov1alpha1.Component{
+				newComponent("component2", []string{"component3"}, nil),
+				newComponent("component1", []string{"component3", "component4"}, nil),
+				newComponent("component3", nil, nil),
+				newComponent("component4", nil, nil),
+			},
+			wantNudgedBy: []string{"component1", "component2"},
+		},
+		{
+			name:          "stale nudging components are removed from the status",
+			componentName: "component2",
+			components: []appstudiov1alpha1.Component{
+				newComponent("component1", []string{"component2"}, nil),
+				newComponent("component2", nil, []string{"component1", "deleted-component"}),
+			},
+			wantNudgedBy: []string{"component1"},
+		},
+		{
+			name:          "component no longer nudged has its status cleared",
+			componentName: "component2",
+			components: []appstudiov1alpha1.Component{
+				newComponent("component1", nil, nil),
+				newComponent("component2", nil, []string{"component1"}),
+			},
+		},
+		{
+			name:          "self references are ignored",
+			componentName: "component1",
+			components: []appstudiov1alpha1.Component{
+				newComponent("component1", []string{"component1"}, nil),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fakeClient := newFakeClient(t)
+			for i := range test.components {
+				err := fakeClient.Create(context.Background(), &test.components[i])
+				require.NoError(t, err)
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			r := &BuildNudgesReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Log:      ctrl.Log.WithName("controllers").WithName("BuildNudges"),
+				Recorder: recorder,
+			}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: test.componentName}})
+			require.NoError(t, err)
+			assert.Empty(t, recorder.Events)
+
+			component := &appstudiov1alpha1.Component{}
+			err = fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: test.componentName}, component)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantNudgedBy, component.Status.BuildNudgedBy)
+		})
+	}
+
+	t.Run("missing component is ignored", func(t *testing.T) {
+		fakeClient := newFakeClient(t)
+		r := &BuildNudgesReconciler{
+			Client: fakeClient,
+			Scheme: fakeClient.Scheme(),
+		}
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "missing"}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("status update failure is recorded as an event", func(t *testing.T) {
+		fakeClient := newFakeClient(t)
+		for _, comp := range []appstudiov1alpha1.Component{
+			newComponent("component1", []string{"component2"}, nil),
+			newComponent("component2", nil, nil),
+		} {
+			comp := comp
+			err := fakeClient.Create(context.Background(), &comp)
+			require.NoError(t, err)
+		}
+
+		recorder := record.NewFakeRecorder(10)
+		r := &BuildNudgesReconciler{
+			Client: interceptor.NewClient(fakeClient, interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					return errors.New("some error")
+				},
+			}),
+			Scheme:   fakeClient.Scheme(),
+			Log:      ctrl.Log.WithName("controllers").WithName("BuildNudges"),
+			Recorder: recorder,
+		}
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "component2"}})
+		assert.EqualError(t, err, "some error")
+		require.Len(t, recorder.Events, 1)
+		assert.Equal(t, "Warning BuildNudgesCleanupFailed Unable to update status.build-nudged-by to [component1]: some error", <-recorder.Events)
+	})
+
+	t.Run("status update conflict is not recorded as an event", func(t *testing.T) {
+		fakeClient := newFakeClient(t)
+		for _, comp := range []appstudiov1alpha1.Component{
+			newComponent("component1", []string{"component2"}, nil),
+			newComponent("component2", nil, nil),
+		} {
+			comp := comp
+			err := fakeClient.Create(context.Background(), &comp)
+			require.NoError(t, err)
+		}
+
+		recorder := record.NewFakeRecorder(10)
+		r := &BuildNudgesReconciler{
+			Client: interceptor.NewClient(fakeClient, interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					return k8sErrors.NewConflict(schema.GroupResource{Group: "appstudio.redhat.com", Resource: "components"}, obj.GetName(), errors.New("the object has been modified"))
+				},
+			}),
+			Scheme:   fakeClient.Scheme(),
+			Log:      ctrl.Log.WithName("controllers").WithName("BuildNudges"),
+			Recorder: recorder,
+		}
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "component2"}})
+		assert.True(t, k8sErrors.IsConflict(err))
+		assert.Empty(t, recorder.Events)
+	})
+}
+
+func TestNudgedComponentsHandler(t *testing.T) {
+	h := nudgedComponentsHandler()
+
+	oldComp := newComponent("component1", []string{"component2", "component3"}, nil)
+	newComp := newComponent("component1", []string{"component3", "component4"}, nil)
+
+	tests := []struct {
+		name     string
+		trigger  func(q workqueue.RateLimitingInterface)
+		wantKeys []string
+	}{
+		{
+			name: "create enqueues the nudged components",
+			trigger: func(q workqueue.RateLimitingInterface) {
+				h.Create(context.Background(), event.CreateEvent{Object: &oldComp}, q)
+			},
+			wantKeys: []string{"component2", "component3"},
+		},
+		{
+			name: "update enqueues the previously and currently nudged components",
+			trigger: func(q workqueue.RateLimitingInterface) {
+				h.Update(context.Background(), event.UpdateEvent{ObjectOld: &oldComp, ObjectNew: &newComp}, q)
+			},
+			wantKeys: []string{"component2", "component3", "component4"},
+		},
+		{
+			name: "update without build-nudges-ref change is ignored",
+			trigger: func(q workqueue.RateLimitingInterface) {
+				h.Update(context.Background(), event.UpdateEvent{ObjectOld: &oldComp, ObjectNew: oldComp.DeepCopy()}, q)
+			},
+		},
+		{
+			name: "delete enqueues the nudged components",
+			trigger: func(q workqueue.RateLimitingInterface) {
+				h.Delete(context.Background(), event.DeleteEvent{Object: &newComp}, q)
+			},
+			wantKeys: []string{"component3", "component4"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer q.ShutDown()
+			test.trigger(q)
+
+			var gotKeys []string
+			for q.Len() > 0 {
+				item, _ := q.Get()
+				gotKeys = append(gotKeys, item.(ctrl.Request).Name)
+				q.Done(item)
+			}
+			assert.ElementsMatch(t, test.wantKeys, gotKeys)
+		})
+	}
+}
+
+// newComponent returns a Component in the default namespace with the given build-nudges-ref and build-nudged-by fields
+func newComponent(name string, buildNudgesRef []string, buildNudgedBy []string) appstudiov1alpha1.Component {
+	return appstudiov1alpha1.Component{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: "appstudio.redhat.com/v1alpha1",
+			Kind:       "Component",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ComponentName:  name,
+			Application:    "application1",
+			BuildNudgesRef: buildNudgesRef,
+		},
+		Status: appstudiov1alpha1.ComponentStatus{
+			BuildNudgedBy: buildNudgedBy,
+		},
+	}
+}
+
+// newFakeClient returns a fake controller-runtime Kube client with the appstudio types registered
+func newFakeClient(t *testing.T) client.WithWatch {
+	s := scheme.Scheme
+	err := appstudiov1alpha1.AddToScheme(s)
+	require.NoError(t, err)
+	return fake.NewClientBuilder().
+		WithScheme(s).
+		WithStatusSubresource(&appstudiov1alpha1.Component{}).
+		Build()
+}
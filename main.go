@@ -35,10 +35,12 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	routev1 "github.com/openshift/api/route/v1"
 
 	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/controllers"
 	"github.com/redhat-appstudio/application-service/webhooks"
 
 	// Enable pprof for profiling
@@ -96,7 +98,7 @@ func main() {
 	options := ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
-		Port:                   9443,
+		WebhookServer:          ctrlwebhook.NewServer(webhookServerOptions()),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "f50829e1.redhat.com",
@@ -113,6 +115,25 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = (&controllers.BuildNudgesReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      ctrl.Log.WithName("controllers").WithName("BuildNudges"),
+		Recorder: mgr.GetEventRecorderFor("buildnudges"),
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BuildNudges")
+		os.Exit(1)
+	}
+	if err = (&controllers.ApplicationOwnershipReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      ctrl.Log.WithName("controllers").WithName("ApplicationOwnership"),
+		Recorder: mgr.GetEventRecorderFor("applicationownership"),
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ApplicationOwnership")
+		os.Exit(1)
+	}
+
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		setupLog.Info("setting up webhooks")
 		setUpWebhooks(mgr)
@@ -143,6 +164,13 @@ func setUpWebhooks(mgr ctrl.Manager) {
 		setupLog.Error(err, "unable to setup webhooks")
 		os.Exit(1)
 	}
+}
+
+// webhookServerOptions returns the options of the webhook server.
+func webhookServerOptions() ctrlwebhook.Options {
+	options := ctrlwebhook.Options{
+		Port: 9443,
+	}
 
 	// Retrieve the option to enable HTTP2 on the Webhook server
 	enableWebhookHTTP2 := os.Getenv("ENABLE_WEBHOOK_HTTP2")
@@ -152,11 +180,12 @@ func setUpWebhooks(mgr ctrl.Manager) {
 
 	if enableWebhookHTTP2 == "false" {
 		setupLog.Info("disabling http/2 on the webhook server")
-		server := mgr.GetWebhookServer()
-		server.TLSOpts = append(server.TLSOpts,
+		options.TLSOpts = append(options.TLSOpts,
 			func(c *tls.Config) {
 				c.NextProtos = []string{"http/1.1"}
 			},
 		)
 	}
+
+	return options
 }
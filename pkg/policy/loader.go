@@ -0,0 +1,101 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ConfigMapName is the name of the ConfigMap holding the policies, in the namespace of the operator
+	ConfigMapName = "admission-policies"
+
+	// ConfigMapKey is the key of the YAML list of policies in the ConfigMap
+	ConfigMapKey = "policies.yaml"
+
+	// ModesConfigMapKey is the key of the enforcement modes in the ConfigMap, a YAML map of rule or policy names to
+	// their mode
+	ModesConfigMapKey = "enforcement.yaml"
+)
+
+// Loader loads the policies from a ConfigMap. The policies are only compiled again when the ConfigMap changes.
+// The ConfigMap is meant to be read from a cache watching it, as it's loaded on every admission request.
+type Loader struct {
+	client    client.Reader
+	namespace string
+	name      string
+
+	mu              sync.Mutex
+	resourceVersion string
+	set             *Set
+	err             error
+}
+
+// NewLoader returns a Loader of the policies stored in the given ConfigMap
+func NewLoader(c client.Reader, namespace string, name string) *Loader {
+	return &Loader{
+		client:    c,
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+// Load returns the current policies. If the ConfigMap doesn't exist, it returns an empty set.
+// It returns an error if the ConfigMap can't be retrieved or if its policies are invalid.
+func (l *Loader) Load(ctx context.Context) (*Set, error) {
+	var configMap corev1.ConfigMap
+	err := l.client.Get(ctx, types.NamespacedName{Namespace: l.namespace, Name: l.name}, &configMap)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return &Set{}, nil
+		}
+		return nil, fmt.Errorf("unable to get the policies ConfigMap %s/%s: %v", l.namespace, l.name, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if configMap.ResourceVersion == "" || configMap.ResourceVersion != l.resourceVersion {
+		l.set, l.err = Parse([]byte(configMap.Data[ConfigMapKey]))
+		if l.err == nil {
+			l.set.modes, l.err = parseModes([]byte(configMap.Data[ModesConfigMapKey]))
+		}
+		if l.err != nil {
+			l.err = fmt.Errorf("ConfigMap %s/%s: %v", l.namespace, l.name, l.err)
+		}
+		l.resourceVersion = configMap.ResourceVersion
+	}
+	return l.set, l.err
+}
+
+// parseModes parses a YAML map of rule or policy names to their enforcement mode
+func parseModes(data []byte) (Modes, error) {
+	var modes Modes
+	if err := yaml.UnmarshalStrict(data, &modes); err != nil {
+		return nil, fmt.Errorf("unable to parse the enforcement modes: %v", err)
+	}
+	if err := modes.validate(); err != nil {
+		return nil, fmt.Errorf("invalid enforcement modes: %v", err)
+	}
+	return modes, nil
+}
@@ -0,0 +1,103 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Mode is the enforcement mode of a validation rule or policy
+type Mode string
+
+const (
+	// ModeEnforce denies the requests that violate the rule. It is the default mode.
+	ModeEnforce Mode = "enforce"
+
+	// ModeWarn admits the requests that violate the rule, returning the violation as a warning
+	ModeWarn Mode = "warn"
+
+	// ModeAudit admits the requests that violate the rule, only logging and counting the violation
+	ModeAudit Mode = "audit"
+)
+
+// validate returns an error if the mode is not enforce, warn or audit
+func (m Mode) validate() error {
+	switch m {
+	case ModeEnforce, ModeWarn, ModeAudit:
+		return nil
+	}
+	return fmt.Errorf("invalid enforcement mode %q, expected %s, %s or %s", m, ModeEnforce, ModeWarn, ModeAudit)
+}
+
+// Modes maps the names of validation rules and policies to their enforcement mode
+type Modes map[string]Mode
+
+// ParseModes parses a comma-separated list of rule=mode pairs, e.g. spec.containerImage=audit,quota=warn
+func ParseModes(s string) (Modes, error) {
+	modes := Modes{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		rule, mode, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(rule) == "" {
+			return nil, fmt.Errorf("invalid enforcement mode %q, expected <rule>=<mode>", pair)
+		}
+		modes[strings.TrimSpace(rule)] = Mode(strings.TrimSpace(mode))
+	}
+	return modes, modes.validate()
+}
+
+// Mode returns the enforcement mode of the given rule, or fallback if it has none. An empty fallback means enforce.
+func (m Modes) Mode(rule string, fallback Mode) Mode {
+	if mode, ok := m[rule]; ok {
+		return mode
+	}
+	if fallback == "" {
+		return ModeEnforce
+	}
+	return fallback
+}
+
+// Merge returns the modes of m, overridden by the ones of overrides
+func (m Modes) Merge(overrides Modes) Modes {
+	merged := make(Modes, len(m)+len(overrides))
+	for rule, mode := range m {
+		merged[rule] = mode
+	}
+	for rule, mode := range overrides {
+		merged[rule] = mode
+	}
+	return merged
+}
+
+// validate returns an error naming every rule with an invalid mode
+func (m Modes) validate() error {
+	var invalid []string
+	for rule, mode := range m {
+		if err := mode.validate(); err != nil {
+			invalid = append(invalid, fmt.Sprintf("rule %s: %v", rule, err))
+		}
+	}
+	if len(invalid) != 0 {
+		sort.Strings(invalid)
+		return fmt.Errorf("%s", strings.Join(invalid, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policy evaluates organization-specific admission rules, written as CEL expressions, against the objects
+// admitted by the webhooks
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// costLimit bounds the cost of evaluating a single expression, so that a policy can't stall the webhooks
+const costLimit = 1000000
+
+// Policy is an admission rule: requests are denied when its expression evaluates to false
+type Policy struct {
+	// Name identifies the policy in denial messages
+	Name string `json:"name"`
+
+	// Kinds are the kinds of the objects the policy applies to, e.g. Component. If empty, it applies to every kind.
+	Kinds []string `json:"kinds,omitempty"`
+
+	// Operations are the operations the policy applies to, CREATE or UPDATE. If empty, it applies to both.
+	Operations []string `json:"operations,omitempty"`
+
+	// Expression is the CEL expression that must evaluate to true for the request to be admitted. It can use the
+	// object, oldObject, namespaceObject and request variables, as in Kubernetes ValidatingAdmissionPolicies.
+	Expression string `json:"expression"`
+
+	// Message is returned to the user when the policy denies a request
+	Message string `json:"message,omitempty"`
+
+	// Mode is the enforcement mode of the policy, enforce by default. It can be overridden by the enforcement modes
+	// of the ConfigMap.
+	Mode Mode `json:"mode,omitempty"`
+}
+
+// Input is the admission request the policies are evaluated against
+type Input struct {
+	// Kind is the kind of the admitted object, e.g. Component
+	Kind string
+
+	// Operation is CREATE or UPDATE
+	Operation string
+
+	// Object is the admitted object
+	Object runtime.Object
+
+	// OldObject is the object being updated, nil on creation
+	OldObject runtime.Object
+
+	// Namespace is the namespace of the admitted object, nil if it couldn't be retrieved
+	Namespace *corev1.Namespace
+
+	// UserInfo is the user that sent the request
+	UserInfo authenticationv1.UserInfo
+}
+
+// Violation is a policy that denied a request
+type Violation struct {
+	// Policy is the name of the policy
+	Policy string
+
+	// Message is the message of the policy, or the reason why it couldn't be evaluated
+	Message string
+
+	// Mode is the enforcement mode of the policy, empty if it doesn't set one
+	Mode Mode
+}
+
+// String returns the violation as displayed to the user
+func (v Violation) String() string {
+	return fmt.Sprintf("denied by policy %s: %s", v.Policy, v.Message)
+}
+
+// compiledPolicy is a policy with its compiled expression
+type compiledPolicy struct {
+	Policy
+	program cel.Program
+}
+
+// Set is a set of compiled policies, ready to be evaluated, along with the enforcement modes of the validation rules
+// and policies
+type Set struct {
+	policies []compiledPolicy
+	modes    Modes
+}
+
+// Parse parses and compiles a YAML list of policies
+func Parse(data []byte) (*Set, error) {
+	var policies []Policy
+	if err := yaml.UnmarshalStrict(data, &policies); err != nil {
+		return nil, fmt.Errorf("unable to parse the policies: %v", err)
+	}
+	return Compile(policies)
+}
+
+// Compile compiles the expressions of the given policies. It returns an error naming every policy that is invalid.
+func Compile(policies []Policy) (*Set, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("object", cel.DynType),
+		cel.Variable("oldObject", cel.DynType),
+		cel.Variable("namespaceObject", cel.DynType),
+		cel.Variable("request", cel.DynType),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &Set{}
+	var errs []string
+	names := map[string]bool{}
+	for _, policy := range policies {
+		if policy.Name == "" {
+			errs = append(errs, "a policy has no name")
+			continue
+		}
+		if names[policy.Name] {
+			errs = append(errs, fmt.Sprintf("policy %s is defined more than once", policy.Name))
+			continue
+		}
+		names[policy.Name] = true
+		if policy.Mode != "" {
+			if err := policy.Mode.validate(); err != nil {
+				errs = append(errs, fmt.Sprintf("policy %s: %v", policy.Name, err))
+				continue
+			}
+		}
+
+		ast, issues := env.Compile(policy.Expression)
+		if issues != nil && issues.Err() != nil {
+			errs = append(errs, fmt.Sprintf("policy %s has an invalid expression: %v", policy.Name, issues.Err()))
+			continue
+		}
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			errs = append(errs, fmt.Sprintf("policy %s has an expression of type %s, expected bool", policy.Name, ast.OutputType()))
+			continue
+		}
+		program, err := env.Program(ast, cel.CostLimit(costLimit))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("policy %s has an invalid expression: %v", policy.Name, err))
+			continue
+		}
+		set.policies = append(set.policies, compiledPolicy{Policy: policy, program: program})
+	}
+
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("invalid policies: %s", strings.Join(errs, "; "))
+	}
+	return set, nil
+}
+
+// Len returns the number of policies in the set
+func (s *Set) Len() int {
+	return len(s.policies)
+}
+
+// Modes returns the enforcement modes of the validation rules and policies, nil if none is set
+func (s *Set) Modes() Modes {
+	return s.modes
+}
+
+// Evaluate evaluates the policies that apply to the kind and operation of the input, and returns the ones that
+// denied it. A policy that can't be evaluated, e.g. because its expression accesses a missing field, denies the input.
+func (s *Set) Evaluate(input Input) ([]Violation, error) {
+	if len(s.policies) == 0 {
+		return nil, nil
+	}
+
+	activation, err := newActivation(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []Violation
+	for _, policy := range s.policies {
+		if !policy.appliesTo(input.Kind, input.Operation) {
+			continue
+		}
+
+		result, _, err := policy.program.Eval(activation)
+		if err != nil {
+			violations = append(violations, Violation{Policy: policy.Name, Message: fmt.Sprintf("unable to evaluate the policy: %v", err), Mode: policy.Mode})
+			continue
+		}
+		allowed, ok := result.Value().(bool)
+		if !ok {
+			violations = append(violations, Violation{Policy: policy.Name, Message: fmt.Sprintf("expression evaluated to %v, expected a bool", result.Value()), Mode: policy.Mode})
+			continue
+		}
+		if !allowed {
+			message := policy.Message
+			if message == "" {
+				message = fmt.Sprintf("failed expression: %s", policy.Expression)
+			}
+			violations = append(violations, Violation{Policy: policy.Name, Message: message, Mode: policy.Mode})
+		}
+	}
+	return violations, nil
+}
+
+// appliesTo returns true if the policy applies to the given kind and operation
+func (p *compiledPolicy) appliesTo(kind string, operation string) bool {
+	return matches(p.Kinds, kind) && matches(p.Operations, operation)
+}
+
+// matches returns true if values is empty or contains value, ignoring case
+func matches(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// newActivation converts the input to the variables the expressions are evaluated with
+func newActivation(input Input) (map[string]interface{}, error) {
+	object, err := toUnstructured(input.Object)
+	if err != nil {
+		return nil, err
+	}
+	oldObject, err := toUnstructured(input.OldObject)
+	if err != nil {
+		return nil, err
+	}
+	var namespaceObject interface{}
+	if input.Namespace != nil {
+		if namespaceObject, err = toUnstructured(input.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	// The user info fields are always set, so that expressions don't need to check for their presence
+	groups := make([]interface{}, 0, len(input.UserInfo.Groups))
+	for _, group := range input.UserInfo.Groups {
+		groups = append(groups, group)
+	}
+	userInfo := map[string]interface{}{
+		"username": input.UserInfo.Username,
+		"uid":      input.UserInfo.UID,
+		"groups":   groups,
+	}
+
+	return map[string]interface{}{
+		"object":          object,
+		"oldObject":       oldObject,
+		"namespaceObject": namespaceObject,
+		"request": map[string]interface{}{
+			"kind":      input.Kind,
+			"operation": input.Operation,
+			"userInfo":  userInfo,
+		},
+	}, nil
+}
+
+// toUnstructured converts obj to a map, or returns nil if obj is nil
+func toUnstructured(obj runtime.Object) (interface{}, error) {
+	if obj == nil {
+		return nil, nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
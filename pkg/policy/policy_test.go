@@ -0,0 +1,303 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testPolicies = `
+- name: pin-revision-in-prod
+  kinds: [Component]
+  expression: |
+    namespaceObject == null || !has(namespaceObject.metadata.labels) ||
+    namespaceObject.metadata.labels.tier != "prod" ||
+    (has(object.spec.source.git) && has(object.spec.source.git.revision))
+  message: components in prod namespaces must pin a git revision
+- name: immutable-secret
+  kinds: [Component]
+  operations: [UPDATE]
+  expression: "!has(oldObject.spec.secret) || (has(object.spec.secret) && object.spec.secret == oldObject.spec.secret)"
+  message: the secret of a component cannot be changed
+- name: admins-only
+  kinds: [Application]
+  expression: "'admins' in request.userInfo.groups"
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies string
+		wantLen  int
+		wantErr  string
+	}{
+		{
+			name:     "valid policies",
+			policies: testPolicies,
+			wantLen:  3,
+		},
+		{
+			name:     "no policies",
+			policies: "",
+		},
+		{
+			name:     "malformed yaml",
+			policies: "- name: [",
+			wantErr:  "unable to parse the policies",
+		},
+		{
+			name:     "unknown field",
+			policies: "- name: a\n  expresion: 'true'",
+			wantErr:  "unable to parse the policies",
+		},
+		{
+			name:     "missing name",
+			policies: "- expression: 'true'",
+			wantErr:  "invalid policies: a policy has no name",
+		},
+		{
+			name:     "duplicate name",
+			policies: "- name: a\n  expression: 'true'\n- name: a\n  expression: 'false'",
+			wantErr:  "invalid policies: policy a is defined more than once",
+		},
+		{
+			name:     "invalid expression",
+			policies: "- name: a\n  expression: 'object.spec.'",
+			wantErr:  "invalid policies: policy a has an invalid expression",
+		},
+		{
+			name:     "expression that is not a bool",
+			policies: "- name: a\n  expression: '1 + 1'",
+			wantErr:  "invalid policies: policy a has an expression of type int, expected bool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := Parse([]byte(tt.policies))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLen, set.Len())
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	set, err := Parse([]byte(testPolicies))
+	require.NoError(t, err)
+
+	prodNamespace := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "prod", Labels: map[string]string{"tier": "prod"}}}
+	devNamespace := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "dev"}}
+	newComponent := func(revision string, secret string) *appstudiov1alpha1.Component {
+		return &appstudiov1alpha1.Component{
+			ObjectMeta: v1.ObjectMeta{Name: "component1"},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				Secret: secret,
+				Source: appstudiov1alpha1.ComponentSource{
+					ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+						GitSource: &appstudiov1alpha1.GitSource{URL: "https://github.com/org/repo", Revision: revision},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		input Input
+		want  []Violation
+	}{
+		{
+			name:  "component with a revision in a prod namespace",
+			input: Input{Kind: "Component", Operation: "CREATE", Object: newComponent("main", ""), Namespace: prodNamespace},
+		},
+		{
+			name:  "component without a revision in a dev namespace",
+			input: Input{Kind: "Component", Operation: "CREATE", Object: newComponent("", ""), Namespace: devNamespace},
+		},
+		{
+			name:  "component without a revision in a prod namespace",
+			input: Input{Kind: "Component", Operation: "CREATE", Object: newComponent("", ""), Namespace: prodNamespace},
+			want:  []Violation{{Policy: "pin-revision-in-prod", Message: "components in prod namespaces must pin a git revision"}},
+		},
+		{
+			name:  "update policies do not apply on creation",
+			input: Input{Kind: "Component", Operation: "CREATE", Object: newComponent("main", "secret2")},
+		},
+		{
+			name:  "changed secret",
+			input: Input{Kind: "Component", Operation: "UPDATE", Object: newComponent("main", "secret2"), OldObject: newComponent("main", "secret1")},
+			want:  []Violation{{Policy: "immutable-secret", Message: "the secret of a component cannot be changed"}},
+		},
+		{
+			name: "application created by an admin",
+			input: Input{Kind: "Application", Operation: "CREATE", Object: &appstudiov1alpha1.Application{},
+				UserInfo: authenticationv1.UserInfo{Username: "admin", Groups: []string{"admins"}}},
+		},
+		{
+			name: "application created by another user, without policy message",
+			input: Input{Kind: "Application", Operation: "CREATE", Object: &appstudiov1alpha1.Application{},
+				UserInfo: authenticationv1.UserInfo{Username: "user"}},
+			want: []Violation{{Policy: "admins-only", Message: "failed expression: 'admins' in request.userInfo.groups"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := set.Evaluate(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, violations)
+		})
+	}
+}
+
+func TestEvaluateError(t *testing.T) {
+	set, err := Parse([]byte("- name: missing-field\n  expression: object.spec.missing == 'value'"))
+	require.NoError(t, err)
+
+	violations, err := set.Evaluate(Input{Kind: "Application", Operation: "CREATE", Object: &appstudiov1alpha1.Application{}})
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "missing-field", violations[0].Policy)
+	assert.Contains(t, violations[0].String(), "denied by policy missing-field: unable to evaluate the policy: no such key: missing")
+}
+
+func TestLoader(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{Name: ConfigMapName, Namespace: "application-service"},
+		Data:       map[string]string{ConfigMapKey: testPolicies},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(configMap).Build()
+	ctx := context.Background()
+
+	// A missing ConfigMap means no policies
+	set, err := NewLoader(fakeClient, "other-namespace", ConfigMapName).Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, set.Len())
+
+	loader := NewLoader(fakeClient, "application-service", ConfigMapName)
+	set, err = loader.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, set.Len())
+
+	// The policies are compiled again once the ConfigMap changes
+	configMap.Data[ConfigMapKey] = "- name: a\n  expression: '1'"
+	require.NoError(t, fakeClient.Update(ctx, configMap))
+	_, err = loader.Load(ctx)
+	assert.EqualError(t, err, "ConfigMap application-service/admission-policies: invalid policies: policy a has an expression of type int, expected bool")
+}
+
+func TestParseModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		modes   string
+		want    Modes
+		wantErr string
+	}{
+		{
+			name:  "empty",
+			modes: "",
+			want:  Modes{},
+		},
+		{
+			name:  "several rules",
+			modes: "spec.containerImage=audit, quota = warn,pin-revision=enforce",
+			want:  Modes{"spec.containerImage": ModeAudit, "quota": ModeWarn, "pin-revision": ModeEnforce},
+		},
+		{
+			name:    "missing mode",
+			modes:   "quota",
+			wantErr: "invalid enforcement mode \"quota\", expected <rule>=<mode>",
+		},
+		{
+			name:    "unknown mode",
+			modes:   "quota=ignore",
+			wantErr: "rule quota: invalid enforcement mode \"ignore\", expected enforce, warn or audit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modes, err := ParseModes(tt.modes)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, modes)
+		})
+	}
+}
+
+func TestModes(t *testing.T) {
+	startup := Modes{"quota": ModeWarn, "spec.containerImage": ModeAudit}
+	modes := startup.Merge(Modes{"quota": ModeEnforce, "pin-revision": ModeWarn})
+
+	assert.Equal(t, ModeEnforce, modes.Mode("quota", ""))
+	assert.Equal(t, ModeAudit, modes.Mode("spec.containerImage", ""))
+	assert.Equal(t, ModeWarn, modes.Mode("pin-revision", ModeAudit))
+	assert.Equal(t, ModeAudit, modes.Mode("other-policy", ModeAudit))
+	assert.Equal(t, ModeEnforce, modes.Mode("spec.route", ""))
+	assert.Equal(t, ModeEnforce, Modes(nil).Mode("spec.route", ""))
+}
+
+func TestLoaderModes(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{Name: ConfigMapName, Namespace: "application-service"},
+		Data: map[string]string{
+			ConfigMapKey:      "- name: a\n  expression: 'true'\n  mode: warn",
+			ModesConfigMapKey: "quota: audit",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(configMap).Build()
+	ctx := context.Background()
+
+	loader := NewLoader(fakeClient, "application-service", ConfigMapName)
+	set, err := loader.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Modes{"quota": ModeAudit}, set.Modes())
+
+	// The modes are reloaded along with the policies once the ConfigMap changes
+	configMap.Data[ModesConfigMapKey] = "quota: warn\nspec.containerImage: audit"
+	require.NoError(t, fakeClient.Update(ctx, configMap))
+	set, err = loader.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Modes{"quota": ModeWarn, "spec.containerImage": ModeAudit}, set.Modes())
+
+	configMap.Data[ModesConfigMapKey] = "quota: ignore"
+	require.NoError(t, fakeClient.Update(ctx, configMap))
+	_, err = loader.Load(ctx)
+	assert.EqualError(t, err, "ConfigMap application-service/admission-policies: invalid enforcement modes: rule quota: invalid enforcement mode \"ignore\", expected enforce, warn or audit")
+
+	configMap.Data[ConfigMapKey] = "- name: a\n  expression: 'true'\n  mode: ignore"
+	require.NoError(t, fakeClient.Update(ctx, configMap))
+	_, err = loader.Load(ctx)
+	assert.EqualError(t, err, "ConfigMap application-service/admission-policies: invalid policies: policy a: invalid enforcement mode \"ignore\", expected enforce, warn or audit")
+}
@@ -0,0 +1,149 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// fullCommitSHA matches full SHA-1 and SHA-256 git commit hashes
+var fullCommitSHA = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// scpLikeGitURL matches scp-like git URLs, such as git@github.com:org/repo.git
+var scpLikeGitURL = regexp.MustCompile(`^(?:([^@/:]+)@)?([^@/:]+):([^/].*)$`)
+
+// GitURL is a parsed git repository URL
+type GitURL struct {
+	// Scheme is http, https or ssh. scp-like URLs have the ssh scheme.
+	Scheme string
+	// User is the user in the URL, e.g. git for git@github.com:org/repo
+	User string
+	// Host is the lower case host name, without its "www." prefix and port
+	Host string
+	// Port is the port in the URL, if any
+	Port string
+	// Path is the path of the repository on the host, without leading and trailing slashes or ".git" suffix,
+	// e.g. org/repo
+	Path string
+}
+
+// ParseGitURL parses an http(s), ssh or scp-like git repository URL, such as https://github.com/org/repo,
+// ssh://git@github.com/org/repo.git or git@github.com:org/repo.git.
+// It returns an error if the URL has another scheme, has no host, or if its path doesn't name a repository.
+func ParseGitURL(rawURL string) (*GitURL, error) {
+	var gitURL GitURL
+	var rawPath string
+
+	if strings.Contains(rawURL, "://") {
+		parsedURL, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" && parsedURL.Scheme != "ssh" {
+			return nil, fmt.Errorf("unsupported scheme %q", parsedURL.Scheme)
+		}
+		if parsedURL.Hostname() == "" {
+			return nil, fmt.Errorf("missing host")
+		}
+		gitURL.Scheme = parsedURL.Scheme
+		gitURL.User = parsedURL.User.Username()
+		gitURL.Host = parsedURL.Hostname()
+		gitURL.Port = parsedURL.Port()
+		rawPath = parsedURL.Path
+	} else if matches := scpLikeGitURL.FindStringSubmatch(rawURL); matches != nil {
+		gitURL.Scheme = "ssh"
+		gitURL.User = matches[1]
+		gitURL.Host = matches[2]
+		rawPath = matches[3]
+	} else {
+		return nil, fmt.Errorf("%q is not an http(s), ssh or scp-like git URL", rawURL)
+	}
+	gitURL.Host = strings.TrimPrefix(strings.ToLower(gitURL.Host), "www.")
+
+	var segments []string
+	for _, segment := range strings.Split(strings.TrimSuffix(strings.TrimRight(rawPath, "/"), ".git"), "/") {
+		if segment == ".." {
+			return nil, fmt.Errorf("path %q must not contain '..'", rawPath)
+		}
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) < 2 {
+		return nil, fmt.Errorf("path %q does not name a repository, expected /<organization>/<repository>", rawPath)
+	}
+	gitURL.Path = strings.Join(segments, "/")
+
+	return &gitURL, nil
+}
+
+// HostWithPort returns the host of the URL, followed by its port if it has one
+func (u *GitURL) HostWithPort() string {
+	if u.Port == "" {
+		return u.Host
+	}
+	return u.Host + ":" + u.Port
+}
+
+// Canonical returns the host and path of the repository, e.g. github.com/org/repo. The https, ssh and scp-like URLs
+// of a repository have the same canonical form.
+func (u *GitURL) Canonical() string {
+	return u.Host + "/" + u.Path
+}
+
+// IsFullCommitSHA returns true if revision is a full SHA-1 or SHA-256 commit hash
+func IsFullCommitSHA(revision string) bool {
+	return fullCommitSHA.MatchString(revision)
+}
+
+// ValidateGitRefName returns an error if ref is not a legal git ref name, following the rules of
+// git check-ref-format --allow-onelevel, e.g. main, release/v1.0 or v1.0.0. Names starting with '-' are rejected too,
+// as git refuses to create such branches.
+func ValidateGitRefName(ref string) error {
+	switch {
+	case ref == "":
+		return fmt.Errorf("ref name must not be empty")
+	case ref == "@":
+		return fmt.Errorf("%q is not a valid git ref name: it must not be '@'", ref)
+	case strings.HasPrefix(ref, "-"):
+		return fmt.Errorf("%q is not a valid git ref name: it must not start with '-'", ref)
+	case strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") || strings.Contains(ref, "//"):
+		return fmt.Errorf("%q is not a valid git ref name: it must not start or end with '/', or contain '//'", ref)
+	case strings.HasSuffix(ref, "."):
+		return fmt.Errorf("%q is not a valid git ref name: it must not end with '.'", ref)
+	case strings.Contains(ref, ".."):
+		return fmt.Errorf("%q is not a valid git ref name: it must not contain '..'", ref)
+	case strings.Contains(ref, "@{"):
+		return fmt.Errorf("%q is not a valid git ref name: it must not contain '@{'", ref)
+	}
+
+	for _, r := range ref {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return fmt.Errorf("%q is not a valid git ref name: it must not contain control characters, spaces or any of '~^:?*[\\'", ref)
+		}
+	}
+
+	for _, component := range strings.Split(ref, "/") {
+		if strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			return fmt.Errorf("%q is not a valid git ref name: its components must not start with '.' or end with '.lock'", ref)
+		}
+	}
+
+	return nil
+}
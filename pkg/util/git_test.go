@@ -0,0 +1,158 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		want          *GitURL
+		wantCanonical string
+		wantErr       string
+	}{
+		{
+			name:          "https url",
+			url:           "https://github.com/org/repo",
+			want:          &GitURL{Scheme: "https", Host: "github.com", Path: "org/repo"},
+			wantCanonical: "github.com/org/repo",
+		},
+		{
+			name:          "https url with .git suffix, trailing slash and www prefix",
+			url:           "https://WWW.GitHub.com/org/repo.git/",
+			want:          &GitURL{Scheme: "https", Host: "github.com", Path: "org/repo"},
+			wantCanonical: "github.com/org/repo",
+		},
+		{
+			name:          "https url with port and subgroups",
+			url:           "https://gitlab.example.com:8443/group/subgroup/repo",
+			want:          &GitURL{Scheme: "https", Host: "gitlab.example.com", Port: "8443", Path: "group/subgroup/repo"},
+			wantCanonical: "gitlab.example.com/group/subgroup/repo",
+		},
+		{
+			name:          "ssh url",
+			url:           "ssh://git@github.com/org/repo.git",
+			want:          &GitURL{Scheme: "ssh", User: "git", Host: "github.com", Path: "org/repo"},
+			wantCanonical: "github.com/org/repo",
+		},
+		{
+			name:          "ssh url with port",
+			url:           "ssh://git@gitlab.example.com:2222/group/repo.git",
+			want:          &GitURL{Scheme: "ssh", User: "git", Host: "gitlab.example.com", Port: "2222", Path: "group/repo"},
+			wantCanonical: "gitlab.example.com/group/repo",
+		},
+		{
+			name:          "scp-like url",
+			url:           "git@github.com:org/repo.git",
+			want:          &GitURL{Scheme: "ssh", User: "git", Host: "github.com", Path: "org/repo"},
+			wantCanonical: "github.com/org/repo",
+		},
+		{
+			name:          "scp-like url without user",
+			url:           "github.com:org/repo",
+			want:          &GitURL{Scheme: "ssh", Host: "github.com", Path: "org/repo"},
+			wantCanonical: "github.com/org/repo",
+		},
+		{
+			name:    "unsupported scheme",
+			url:     "file://github.com/org/repo",
+			wantErr: "unsupported scheme \"file\"",
+		},
+		{
+			name:    "missing host",
+			url:     "https:///org/repo",
+			wantErr: "missing host",
+		},
+		{
+			name:    "path without repository",
+			url:     "git@github.com:org",
+			wantErr: "path \"org\" does not name a repository, expected /<organization>/<repository>",
+		},
+		{
+			name:    "path with parent directory",
+			url:     "https://github.com/org/../repo",
+			wantErr: "path \"/org/../repo\" must not contain '..'",
+		},
+		{
+			name:    "not a url",
+			url:     "badurl",
+			wantErr: "\"badurl\" is not an http(s), ssh or scp-like git URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitURL, err := ParseGitURL(tt.url)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, gitURL)
+			assert.Equal(t, tt.wantCanonical, gitURL.Canonical())
+		})
+	}
+}
+
+func TestValidateGitRefName(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		wantErr bool
+	}{
+		{name: "one level branch", ref: "main"},
+		{name: "multi level branch", ref: "release/v1.0"},
+		{name: "tag", ref: "v1.0.0"},
+		{name: "empty", ref: "", wantErr: true},
+		{name: "at sign", ref: "@", wantErr: true},
+		{name: "leading dash", ref: "-main", wantErr: true},
+		{name: "leading slash", ref: "/main", wantErr: true},
+		{name: "trailing slash", ref: "main/", wantErr: true},
+		{name: "double slash", ref: "release//v1", wantErr: true},
+		{name: "trailing dot", ref: "main.", wantErr: true},
+		{name: "double dot", ref: "main..dev", wantErr: true},
+		{name: "reflog syntax", ref: "main@{1}", wantErr: true},
+		{name: "space", ref: "my branch", wantErr: true},
+		{name: "control character", ref: "main\x01", wantErr: true},
+		{name: "tilde", ref: "main~1", wantErr: true},
+		{name: "caret", ref: "main^", wantErr: true},
+		{name: "colon", ref: "main:dev", wantErr: true},
+		{name: "glob characters", ref: "feature*", wantErr: true},
+		{name: "backslash", ref: "feature\\x", wantErr: true},
+		{name: "component starting with a dot", ref: "release/.hidden", wantErr: true},
+		{name: "component ending with .lock", ref: "main.lock", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGitRefName(tt.ref)
+			assert.Equal(t, tt.wantErr, err != nil, "unexpected result for %q: %v", tt.ref, err)
+		})
+	}
+}
+
+func TestIsFullCommitSHA(t *testing.T) {
+	assert.True(t, IsFullCommitSHA("4b825dc642cb6eb9a060e54bf8d69288fbee4904"))
+	assert.True(t, IsFullCommitSHA("6ef19b41225c5369f1c104d45d8d85efa9b057b53b14b4b9b939dd74decc5321"))
+	assert.False(t, IsFullCommitSHA("4b825dc"))
+	assert.False(t, IsFullCommitSHA("4B825DC642CB6EB9A060E54BF8D69288FBEE4904"))
+	assert.False(t, IsFullCommitSHA("main"))
+}
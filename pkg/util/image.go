@@ -0,0 +1,116 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultImageRegistry is the registry of image references that don't name one, e.g. nginx:latest
+const DefaultImageRegistry = "docker.io"
+
+// maxImageNameLength is the maximum length of the name of an image, i.e. its registry and repository
+const maxImageNameLength = 255
+
+var (
+	// imagePathComponent matches a component of an image repository, e.g. my-org or my_image
+	imagePathComponent = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+	// imageRegistry matches a registry host, with an optional port, e.g. quay.io or localhost:5000
+	imageRegistry = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?$`)
+	// imageTag matches an image tag, e.g. latest or v1.0.0
+	imageTag = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	// imageDigests maps the supported digest algorithms to the expected length of their hex encoded value
+	imageDigests = map[string]int{"sha256": 64, "sha384": 96, "sha512": 128}
+	// hexValue matches lower case hex encoded values
+	hexValue = regexp.MustCompile(`^[a-f0-9]+$`)
+)
+
+// ImageReference is a parsed OCI image reference
+type ImageReference struct {
+	// Registry is the host of the registry, with its port if any, e.g. quay.io
+	Registry string
+	// Repository is the path of the image in the registry, e.g. my-org/my-image
+	Repository string
+	// Tag is the tag of the image, if any, e.g. latest
+	Tag string
+	// Digest is the digest of the image, if any, e.g. sha256:<hex>
+	Digest string
+}
+
+// ParseImageReference parses an OCI image reference of the form [registry/]repository[:tag][@digest],
+// e.g. quay.io/my-org/my-image:v1.0.0 or my-image@sha256:<hex>. References without a registry default to
+// DefaultImageRegistry. It returns an error if the reference is malformed.
+func ParseImageReference(ref string) (*ImageReference, error) {
+	var image ImageReference
+	name := ref
+
+	if i := strings.Index(name, "@"); i != -1 {
+		image.Digest = name[i+1:]
+		name = name[:i]
+		algorithm, value, found := strings.Cut(image.Digest, ":")
+		length, supported := imageDigests[algorithm]
+		if !found || !supported {
+			return nil, fmt.Errorf("image reference %q has an unsupported digest algorithm, supported ones are sha256, sha384 and sha512", ref)
+		}
+		if len(value) != length || !hexValue.MatchString(value) {
+			return nil, fmt.Errorf("image reference %q has an invalid %s digest", ref, algorithm)
+		}
+	}
+
+	// The tag is after the last colon, unless that colon is part of the registry port
+	if i := strings.LastIndex(name, ":"); i != -1 && !strings.Contains(name[i+1:], "/") {
+		image.Tag = name[i+1:]
+		name = name[:i]
+		if !imageTag.MatchString(image.Tag) {
+			return nil, fmt.Errorf("image reference %q has an invalid tag %q", ref, image.Tag)
+		}
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("image reference %q has no repository", ref)
+	}
+	if len(name) > maxImageNameLength {
+		return nil, fmt.Errorf("image reference %q has a name longer than %d characters", ref, maxImageNameLength)
+	}
+
+	// The first component is the registry if it looks like a host, as in the docker CLI
+	components := strings.Split(name, "/")
+	if len(components) > 1 && (strings.ContainsAny(components[0], ".:") || components[0] == "localhost") {
+		image.Registry = components[0]
+		components = components[1:]
+		if !imageRegistry.MatchString(image.Registry) {
+			return nil, fmt.Errorf("image reference %q has an invalid registry %q", ref, image.Registry)
+		}
+	} else {
+		image.Registry = DefaultImageRegistry
+	}
+
+	for _, component := range components {
+		if !imagePathComponent.MatchString(component) {
+			return nil, fmt.Errorf("image reference %q has an invalid repository %q: it must consist of lower case alphanumeric components separated by '/', optionally with '.', '_' or '-' separators", ref, strings.Join(components, "/"))
+		}
+	}
+	image.Repository = strings.Join(components, "/")
+
+	return &image, nil
+}
+
+// Name returns the registry and repository of the image, e.g. quay.io/my-org/my-image
+func (i *ImageReference) Name() string {
+	return i.Registry + "/" + i.Repository
+}
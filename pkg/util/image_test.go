@@ -0,0 +1,112 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImageReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    *ImageReference
+		wantErr string
+	}{
+		{
+			name: "image without registry",
+			ref:  "nginx",
+			want: &ImageReference{Registry: "docker.io", Repository: "nginx"},
+		},
+		{
+			name: "image with registry and tag",
+			ref:  "quay.io/my-org/my-image:v1.0.0",
+			want: &ImageReference{Registry: "quay.io", Repository: "my-org/my-image", Tag: "v1.0.0"},
+		},
+		{
+			name: "image with registry port and digest",
+			ref:  "localhost:5000/my-image@" + digest,
+			want: &ImageReference{Registry: "localhost:5000", Repository: "my-image", Digest: digest},
+		},
+		{
+			name: "image with tag and digest",
+			ref:  "registry.example.com/team/app/my_image:latest@" + digest,
+			want: &ImageReference{Registry: "registry.example.com", Repository: "team/app/my_image", Tag: "latest", Digest: digest},
+		},
+		{
+			name: "first component without a dot is part of the repository",
+			ref:  "my-org/my-image",
+			want: &ImageReference{Registry: "docker.io", Repository: "my-org/my-image"},
+		},
+		{
+			name:    "upper case repository",
+			ref:     "quay.io/My-Org/my-image",
+			wantErr: "image reference \"quay.io/My-Org/my-image\" has an invalid repository \"My-Org/my-image\"",
+		},
+		{
+			name:    "invalid tag",
+			ref:     "quay.io/my-org/my-image:-latest",
+			wantErr: "image reference \"quay.io/my-org/my-image:-latest\" has an invalid tag \"-latest\"",
+		},
+		{
+			name:    "unsupported digest algorithm",
+			ref:     "quay.io/my-org/my-image@md5:d41d8cd98f00b204e9800998ecf8427e",
+			wantErr: "image reference \"quay.io/my-org/my-image@md5:d41d8cd98f00b204e9800998ecf8427e\" has an unsupported digest algorithm",
+		},
+		{
+			name:    "truncated digest",
+			ref:     "quay.io/my-org/my-image@sha256:abc",
+			wantErr: "image reference \"quay.io/my-org/my-image@sha256:abc\" has an invalid sha256 digest",
+		},
+		{
+			name:    "empty repository",
+			ref:     ":latest",
+			wantErr: "image reference \":latest\" has no repository",
+		},
+		{
+			name:    "empty path component",
+			ref:     "quay.io//my-image",
+			wantErr: "has an invalid repository",
+		},
+		{
+			name:    "invalid registry",
+			ref:     "-quay.io/my-image",
+			wantErr: "image reference \"-quay.io/my-image\" has an invalid registry \"-quay.io\"",
+		},
+		{
+			name:    "invalid registry port",
+			ref:     "quay.io:port/my-image",
+			wantErr: "image reference \"quay.io:port/my-image\" has an invalid registry \"quay.io:port\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, err := ParseImageReference(tt.ref)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, image)
+		})
+	}
+}
@@ -19,25 +19,73 @@ package webhooks
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// allowCascadeDeleteAnnotation is the annotation that must be set to "true" on an Application to delete it while it
+// still owns Components or Snapshots, when the deletion guard is enabled
+const allowCascadeDeleteAnnotation = "appstudio.redhat.com/allow-cascade-delete"
+
+// allowRepositoryMigrationAnnotation is the annotation that must be set to "true" on an Application to change the URL
+// of its GitOps or app model repository
+const allowRepositoryMigrationAnnotation = "appstudio.redhat.com/allow-repository-migration"
+
+// reservedPrefix is the prefix of the labels and annotations that can only be changed by the operator itself
+const reservedPrefix = "appstudio.redhat.com/"
+
+// userSettableAnnotations are the annotations in the reservedPrefix that users set to opt into a behavior
+var userSettableAnnotations = []string{allowCascadeDeleteAnnotation, allowRepositoryMigrationAnnotation}
+
+// Standard labels stamped on Applications by the mutating webhook
+const (
+	nameLabel      = "app.kubernetes.io/name"
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "application-service"
 )
 
 // Webhook describes the data structure for the release webhook
 type ApplicationWebhook struct {
-	client client.Client
-	log    logr.Logger
+	admissionConfig
+
+	// guardDeletion refuses the deletion of Applications that still own Components or Snapshots,
+	// unless they carry the allowCascadeDeleteAnnotation
+	guardDeletion bool
+
+	// quotas are the default limits on the number of Applications per namespace
+	quotas quotas
+
+	// operatorUsername is the username of the operator's service account, the only user allowed to change
+	// the labels and annotations in the reservedPrefix
+	operatorUsername string
 }
 
 //+kubebuilder:webhook:path=/mutate-appstudio-redhat-com-v1alpha1-application,mutating=true,failurePolicy=fail,sideEffects=None,groups=appstudio.redhat.com,resources=applications,verbs=create;update,versions=v1alpha1,name=mapplication.kb.io,admissionReviewVersions=v1
 
 func (w *ApplicationWebhook) Register(mgr ctrl.Manager, log *logr.Logger) error {
-	w.client = mgr.GetClient()
+	if err := w.register(mgr, log, applicationWebhookName); err != nil {
+		return err
+	}
+	w.guardDeletion = os.Getenv("APPLICATION_DELETION_GUARD") == "true"
+	w.quotas = quotasFromEnv(w.log)
+	if os.Getenv("POD_NAMESPACE") != "" && os.Getenv("SERVICE_ACCOUNT_NAME") != "" {
+		w.operatorUsername = fmt.Sprintf("system:serviceaccount:%s:%s", os.Getenv("POD_NAMESPACE"), os.Getenv("SERVICE_ACCOUNT_NAME"))
+	} else {
+		w.log.Error(nil, "POD_NAMESPACE or SERVICE_ACCOUNT_NAME is not set, no user, including the operator, can change the labels and annotations with the reserved prefix", "reservedPrefix", reservedPrefix)
+	}
 
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&appstudiov1alpha1.Application{}).
@@ -46,43 +94,222 @@ func (w *ApplicationWebhook) Register(mgr ctrl.Manager, log *logr.Logger) error
 		Complete()
 }
 
-// +kubebuilder:webhook:path=/validate-appstudio-redhat-com-v1alpha1-application,mutating=false,failurePolicy=fail,sideEffects=None,groups=appstudio.redhat.com,resources=applications,verbs=create;update,versions=v1alpha1,name=vapplication.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-appstudio-redhat-com-v1alpha1-application,mutating=false,failurePolicy=fail,sideEffects=NoneOnDryRun,groups=appstudio.redhat.com,resources=applications,verbs=create;update;delete,versions=v1alpha1,name=vapplication.kb.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshots,verbs=get;list
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
+// It defaults the display name of new Applications to their name, collapses the whitespace in its description and stamps the
+// standard app.kubernetes.io labels. Labels that are already set, e.g. by a GitOps tool, are left untouched.
+// Applications created with generateName get their display name and name label from the generateName prefix.
 func (r *ApplicationWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	app := obj.(*appstudiov1alpha1.Application)
+
+	name := app.Name
+	if name == "" {
+		// The name is generated by the API server after the mutating webhooks are called
+		name = strings.TrimSuffix(app.GenerateName, "-")
+	}
+
+	// The display name is only defaulted on creation, so that clearing it on update is rejected by ValidateUpdate
+	if req, err := admission.RequestFromContext(ctx); (err != nil || req.Operation != admissionv1.Update) && strings.TrimSpace(app.Spec.DisplayName) == "" {
+		app.Spec.DisplayName = name
+	}
+	app.Spec.Description = strings.Join(strings.Fields(app.Spec.Description), " ")
+
+	if app.Labels == nil {
+		app.Labels = map[string]string{}
+	}
+	if _, ok := app.Labels[nameLabel]; !ok && name != "" {
+		app.Labels[nameLabel] = name
+	}
+	if _, ok := app.Labels[managedByLabel]; !ok {
+		app.Labels[managedByLabel] = managedByValue
+	}
 
-	// TODO(user): fill in your defaulting logic.
 	return nil
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *ApplicationWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+func (r *ApplicationWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(applicationWebhookName, createOperation, time.Now(), &err)
 	app := obj.(*appstudiov1alpha1.Application)
 
-	applicationlog := r.log.WithValues("controllerKind", "Application").WithValues("name", app.Name).WithValues("namespace", app.Namespace)
+	applicationlog := requestLogger(ctx, r.log, "Application", app)
 	applicationlog.Info("validating the create request")
+
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
 	// We use the DNS-1035 format for application names, so ensure it conforms to that specification
 	if len(validation.IsDNS1035Label(app.Name)) != 0 {
-		return fmt.Errorf("invalid application name: %q: an application resource name must start with a lower case alphabetical character, be under 63 characters, and can only consist of lower case alphanumeric characters or ‘-’,", app.Name)
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), app.Name, "an application resource name must start with a lower case alphabetical character, be under 63 characters, and can only consist of lower case alphanumeric characters or ‘-’"))
 	}
 	if app.Spec.DisplayName == "" {
-		return fmt.Errorf("display name must be provided when creating an Application")
+		errs = append(errs, field.Required(specPath.Child("displayName"), "display name must be provided when creating an Application"))
 	}
-	return nil
+	errs = append(errs, validateApplicationGitRepository(&app.Spec.GitOpsRepository, nil, false, specPath.Child("gitOpsRepository"))...)
+	errs = append(errs, validateApplicationGitRepository(&app.Spec.AppModelRepository, nil, false, specPath.Child("appModelRepository"))...)
+
+	return r.finish(ctx, applicationlog, "Application", app, nil, errs, nil, func() error {
+		return r.quotas.forNamespace(ctx, r.reader(), applicationlog, app.Namespace).validateApplicationQuota(ctx, r.client, app.Namespace)
+	})
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *ApplicationWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+// The display name cannot be cleared, the repository URLs cannot be changed once set unless the Application is being
+// migrated, and the labels and annotations in the reservedPrefix can only be changed by the operator.
+func (r *ApplicationWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(applicationWebhookName, updateOperation, time.Now(), &err)
 	newApp := newObj.(*appstudiov1alpha1.Application)
-	applicationlog := r.log.WithValues("controllerKind", "Application").WithValues("name", newApp.Name).WithValues("namespace", newApp.Namespace)
+	applicationlog := requestLogger(ctx, r.log, "Application", newApp)
 	applicationlog.Info("validating the update request")
 
-	return nil
+	oldApp := oldObj.(*appstudiov1alpha1.Application)
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if strings.TrimSpace(newApp.Spec.DisplayName) == "" {
+		errs = append(errs, field.Required(specPath.Child("displayName"), "display name cannot be cleared"))
+	}
+
+	if !r.isOperator(ctx) {
+		for _, key := range changedReservedKeys(oldApp.Labels, newApp.Labels, nil) {
+			errs = append(errs, field.Forbidden(field.NewPath("metadata", "labels").Key(key), "can only be changed by application-service"))
+		}
+		for _, key := range changedReservedKeys(oldApp.Annotations, newApp.Annotations, userSettableAnnotations) {
+			errs = append(errs, field.Forbidden(field.NewPath("metadata", "annotations").Key(key), "can only be changed by application-service"))
+		}
+	}
+
+	migrationAllowed := newApp.Annotations[allowRepositoryMigrationAnnotation] == "true"
+	errs = append(errs, validateApplicationGitRepository(&newApp.Spec.GitOpsRepository, &oldApp.Spec.GitOpsRepository, migrationAllowed, specPath.Child("gitOpsRepository"))...)
+	errs = append(errs, validateApplicationGitRepository(&newApp.Spec.AppModelRepository, &oldApp.Spec.AppModelRepository, migrationAllowed, specPath.Child("appModelRepository"))...)
+
+	return r.finish(ctx, applicationlog, "Application", newApp, oldApp, errs, nil, nil)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *ApplicationWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+// When the deletion guard is enabled, Applications that still own Components or Snapshots can only be deleted
+// if they carry the allowCascadeDeleteAnnotation
+func (r *ApplicationWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(applicationWebhookName, deleteOperation, time.Now(), &err)
+	app := obj.(*appstudiov1alpha1.Application)
 
-	// TODO(user): fill in your validation logic upon object deletion.
-	return nil
+	if !r.guardDeletion || app.Annotations[allowCascadeDeleteAnnotation] == "true" {
+		return nil, nil
+	}
+
+	applicationlog := requestLogger(ctx, r.log, "Application", app)
+	applicationlog.Info("validating the delete request")
+
+	var componentList appstudiov1alpha1.ComponentList
+	err = r.client.List(ctx, &componentList, client.InNamespace(app.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	components := 0
+	for _, component := range componentList.Items {
+		if component.Spec.Application == app.Name {
+			components++
+		}
+	}
+
+	// The Snapshots are read from the API server, as the manager doesn't otherwise watch them
+	var snapshotList appstudiov1alpha1.SnapshotList
+	err = r.reader().List(ctx, &snapshotList, client.InNamespace(app.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	snapshots := 0
+	for _, snapshot := range snapshotList.Items {
+		if snapshot.Spec.Application == app.Name {
+			snapshots++
+		}
+	}
+
+	if components != 0 || snapshots != 0 {
+		_, enforcer := newEnforcer(ctx, r.policies, r.enforcementModes, r.recorder, applicationlog, "Application", app)
+		var warnings admission.Warnings
+		denial := deny(deletionGuardRule, fmt.Errorf("application %s still owns %d component(s) and %d snapshot(s): set the annotation %s: \"true\" on the application to delete it along with them", app.Name, components, snapshots, allowCascadeDeleteAnnotation))
+		if enforcer.denies(deletionGuardRule, "", denial.Error(), &warnings) {
+			return nil, denial
+		}
+		enforcer.recordWarnings()
+		return warnings, nil
+	}
+
+	return nil, nil
+}
+
+// validateApplicationGitRepository returns the violations found in the given GitOps or app model repository of an
+// Application. On creation, oldRepo is nil and a repository without URL is accepted, as the field is optional.
+// On update, the URL cannot be changed once set without migrationAllowed, and unchanged fields aren't revalidated.
+func validateApplicationGitRepository(newRepo, oldRepo *appstudiov1alpha1.ApplicationGitRepository, migrationAllowed bool, fldPath *field.Path) field.ErrorList {
+	if oldRepo == nil {
+		if newRepo.URL == "" {
+			return nil
+		}
+		oldRepo = &appstudiov1alpha1.ApplicationGitRepository{}
+	}
+	var errs field.ErrorList
+
+	if newRepo.URL != oldRepo.URL {
+		if oldRepo.URL != "" && !migrationAllowed {
+			errs = append(errs, field.Forbidden(fldPath.Child("url"), fmt.Sprintf("cannot be changed: set the annotation %s: \"true\" on the application to migrate it to another repository", allowRepositoryMigrationAnnotation)))
+		} else if newRepo.URL != "" {
+			if err := validateGitRepositoryURL(newRepo.URL); err != nil {
+				errs = append(errs, field.Invalid(fldPath.Child("url"), newRepo.URL, err.Error()))
+			}
+		}
+	}
+	if newRepo.Branch != oldRepo.Branch {
+		if err := validateGitRepositoryBranch(newRepo.Branch); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("branch"), newRepo.Branch, err.Error()))
+		}
+	}
+	if newRepo.Context != oldRepo.Context {
+		if err := validateGitRepositoryContext(newRepo.Context); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("context"), newRepo.Context, err.Error()))
+		}
+	}
+	return errs
+}
+
+// isOperator returns true if the admission request was sent by the operator's service account
+func (r *ApplicationWebhook) isOperator(ctx context.Context) bool {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil || r.operatorUsername == "" {
+		return false
+	}
+	return req.UserInfo.Username == r.operatorUsername
+}
+
+// changedReservedKeys returns the keys in the reservedPrefix that were added, removed or changed between oldMap and
+// newMap, in alphabetical order, ignoring the exempted keys
+func changedReservedKeys(oldMap, newMap map[string]string, exempted []string) []string {
+	var keys []string
+	for key := range oldMap {
+		keys = append(keys, key)
+	}
+	for key := range newMap {
+		if _, ok := oldMap[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var changed []string
+	for _, key := range keys {
+		if !strings.HasPrefix(key, reservedPrefix) || util.StrInList(key, exempted) {
+			continue
+		}
+		oldValue, oldOk := oldMap[key]
+		newValue, newOk := newMap[key]
+		if oldOk != newOk || oldValue != newValue {
+			changed = append(changed, key)
+		}
+	}
+	return changed
 }
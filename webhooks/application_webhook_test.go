@@ -45,7 +45,7 @@ var _ = Describe("Application validation webhook", func() {
 	)
 
 	Context("Create Application CR with missing displayName", func() {
-		It("Should fail with error saying displayName is required field", func() {
+		It("Should default the displayName to the Application name", func() {
 			ctx := context.Background()
 
 			hasApp := &appstudiov1alpha1.Application{
@@ -57,10 +57,18 @@ var _ = Describe("Application validation webhook", func() {
 					Name:      HASAppName,
 					Namespace: HASAppNamespace,
 				},
-				Spec: appstudiov1alpha1.ApplicationSpec{},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					Description: "  Simple   petclinic\napp ",
+				},
 			}
 
-			Expect(k8sClient.Create(ctx, hasApp)).Should(Not(Succeed()))
+			Expect(k8sClient.Create(ctx, hasApp)).Should(Succeed())
+			Expect(hasApp.Spec.DisplayName).Should(Equal(HASAppName))
+			Expect(hasApp.Spec.Description).Should(Equal(Description))
+			Expect(hasApp.Labels).Should(HaveKeyWithValue("app.kubernetes.io/name", HASAppName))
+			Expect(hasApp.Labels).Should(HaveKeyWithValue("app.kubernetes.io/managed-by", "application-service"))
+
+			Expect(k8sClient.Delete(ctx, hasApp)).Should(Succeed())
 		})
 	})
 
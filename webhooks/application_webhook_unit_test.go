@@ -24,6 +24,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 func TestApplicationValidatingWebhook(t *testing.T) {
@@ -42,6 +48,8 @@ func TestApplicationValidatingWebhook(t *testing.T) {
 
 	tests := []struct {
 		name      string
+		username  string
+		oldApp    *appstudiov1alpha1.Application
 		updateApp appstudiov1alpha1.Application
 		err       string
 	}{
@@ -59,19 +67,220 @@ func TestApplicationValidatingWebhook(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "gitops repository url cannot be changed",
+			err:  "spec.gitOpsRepository.url: Forbidden: cannot be changed",
+			updateApp: appstudiov1alpha1.Application{
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+					AppModelRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "http://appmodelrepo",
+					},
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "https://github.com/org/gitops",
+					},
+				},
+			},
+		},
+		{
+			name: "app model repository url cannot be changed",
+			err:  "spec.appModelRepository.url: Forbidden: cannot be changed",
+			updateApp: appstudiov1alpha1.Application{
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+					AppModelRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "https://github.com/org/appmodel",
+					},
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "http://gitopsrepo",
+					},
+				},
+			},
+		},
+		{
+			name: "repository urls can be changed with the migration annotation",
+			updateApp: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						allowRepositoryMigrationAnnotation: "true",
+					},
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+					AppModelRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "https://github.com/org/appmodel",
+					},
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "https://github.com/org/gitops",
+					},
+				},
+			},
+		},
+		{
+			name: "migrated repository url must be valid",
+			err:  "spec.gitOpsRepository.url: Invalid value: \"ftp://github.com/org/gitops\"",
+			updateApp: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						allowRepositoryMigrationAnnotation: "true",
+					},
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+					AppModelRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "http://appmodelrepo",
+					},
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "ftp://github.com/org/gitops",
+					},
+				},
+			},
+		},
+		{
+			name: "changed repository context must be relative",
+			err:  "spec.gitOpsRepository.context: Invalid value",
+			updateApp: appstudiov1alpha1.Application{
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+					AppModelRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "http://appmodelrepo",
+					},
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL:     "http://gitopsrepo",
+						Context: "../other-repo",
+					},
+				},
+			},
+		},
+		{
+			name: "display name cannot be cleared",
+			err:  "spec.displayName: Required value: display name cannot be cleared",
+			updateApp: appstudiov1alpha1.Application{
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					AppModelRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "http://appmodelrepo",
+					},
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "http://gitopsrepo",
+					},
+				},
+			},
+		},
+		{
+			name: "reserved label cannot be added by a user",
+			err:  "metadata.labels[appstudio.redhat.com/team]: Forbidden: can only be changed by application-service",
+			updateApp: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Labels: map[string]string{
+						"appstudio.redhat.com/team": "frontend",
+					},
+				},
+				Spec: originalApplication.Spec,
+			},
+		},
+		{
+			name: "reserved annotation cannot be removed by a user",
+			err:  "metadata.annotations[appstudio.redhat.com/generation]: Forbidden: can only be changed by application-service",
+			oldApp: &appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						"appstudio.redhat.com/generation": "1",
+					},
+				},
+				Spec: originalApplication.Spec,
+			},
+			updateApp: appstudiov1alpha1.Application{
+				Spec: originalApplication.Spec,
+			},
+		},
+		{
+			name:     "reserved labels and annotations can be changed by the operator",
+			username: "system:serviceaccount:application-service:controller-manager",
+			updateApp: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Labels: map[string]string{
+						"appstudio.redhat.com/team": "frontend",
+					},
+				},
+				Spec: originalApplication.Spec,
+			},
+		},
+		{
+			name:     "reserved labels and annotations cannot be changed by another service account",
+			username: "system:serviceaccount:default:builder",
+			err:      "metadata.labels[appstudio.redhat.com/team]: Forbidden: can only be changed by application-service",
+			updateApp: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Labels: map[string]string{
+						"appstudio.redhat.com/team": "frontend",
+					},
+				},
+				Spec: originalApplication.Spec,
+			},
+		},
+		{
+			name: "user settable annotations and other labels can be changed by a user",
+			updateApp: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Labels: map[string]string{
+						"team": "frontend",
+					},
+					Annotations: map[string]string{
+						allowCascadeDeleteAnnotation: "true",
+					},
+				},
+				Spec: originalApplication.Spec,
+			},
+		},
+		{
+			name: "repository url can be set once",
+			oldApp: &appstudiov1alpha1.Application{
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+				},
+			},
+			updateApp: appstudiov1alpha1.Application{
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+					AppModelRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "https://github.com/org/appmodel",
+					},
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "https://github.com/org/gitops",
+					},
+				},
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			var err error
 
 			appWebhook := ApplicationWebhook{
-				log: zap.New(zap.UseFlagOptions(&zap.Options{
-					Development: true,
-					TimeEncoder: zapcore.ISO8601TimeEncoder,
-				})),
+				admissionConfig: admissionConfig{
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+				operatorUsername: "system:serviceaccount:application-service:controller-manager",
+			}
+
+			ctx := context.Background()
+			if test.username != "" {
+				ctx = admission.NewContextWithRequest(ctx, admission.Request{
+					AdmissionRequest: admissionv1.AdmissionRequest{
+						UserInfo: authenticationv1.UserInfo{Username: test.username},
+					},
+				})
+			}
+
+			oldApp := &originalApplication
+			if test.oldApp != nil {
+				oldApp = test.oldApp
 			}
 
-			err = appWebhook.ValidateUpdate(context.Background(), &originalApplication, &test.updateApp)
+			_, err = appWebhook.ValidateUpdate(ctx, oldApp, &test.updateApp)
 
 			if test.err == "" {
 				assert.Nil(t, err)
@@ -82,30 +291,410 @@ func TestApplicationValidatingWebhook(t *testing.T) {
 	}
 }
 
-func TestApplicationDeleteValidatingWebhook(t *testing.T) {
-
+func TestApplicationCreateValidatingWebhook(t *testing.T) {
 	tests := []struct {
 		name string
 		app  appstudiov1alpha1.Application
 		err  string
 	}{
 		{
-			name: "ValidateDelete should return nil, it's unimplimented",
+			name: "application without repositories is valid",
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "my-app",
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+				},
+			},
+		},
+		{
+			name: "application with valid repositories",
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "my-app",
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL:     "https://github.com/org/gitops",
+						Branch:  "main",
+						Context: "components/my-app",
+					},
+					AppModelRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "https://gitlab.com/group/subgroup/appmodel",
+					},
+				},
+			},
+		},
+		{
+			name: "gitops repository url must use an http(s) scheme",
+			err:  "spec.gitOpsRepository.url: Invalid value: \"ftp://github.com/org/gitops\": unsupported scheme \"ftp\"",
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "my-app",
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "ftp://github.com/org/gitops",
+					},
+				},
+			},
+		},
+		{
+			name: "app model repository url must name a repository",
+			err:  "spec.appModelRepository.url: Invalid value: \"https://github.com/org\": path \"/org\" does not name a repository",
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "my-app",
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+					AppModelRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL: "https://github.com/org",
+					},
+				},
+			},
+		},
+		{
+			name: "gitops repository branch must be a valid ref name",
+			err:  "spec.gitOpsRepository.branch: Invalid value: \"my branch\": \"my branch\" is not a valid git ref name",
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "my-app",
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+					GitOpsRepository: appstudiov1alpha1.ApplicationGitRepository{
+						URL:    "https://github.com/org/gitops",
+						Branch: "my branch",
+					},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			appWebhook := ApplicationWebhook{
+				admissionConfig: admissionConfig{
+					client: NewFakeClient(t),
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+
+			_, err := appWebhook.ValidateCreate(context.Background(), &test.app)
+
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
+
+func TestApplicationQuota(t *testing.T) {
+	tests := []struct {
+		name      string
+		quotas    quotas
+		namespace corev1.Namespace
+		err       string
+	}{
+		{
+			name: "no quota is enforced by default",
+			namespace: corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{Name: "default"},
+			},
+		},
+		{
+			name:   "application below the configured quota can be created",
+			quotas: quotas{maxApplications: 3},
+			namespace: corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{Name: "default"},
+			},
+		},
+		{
+			name:   "application above the configured quota cannot be created",
+			quotas: quotas{maxApplications: 2},
+			err:    "namespace default already contains 2 application(s), the limit is 2",
+			namespace: corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{Name: "default"},
+			},
+		},
+		{
+			name:   "namespace annotation overrides the configured quota",
+			quotas: quotas{maxApplications: 3},
+			err:    "namespace default already contains 2 application(s), the limit is 1",
+			namespace: corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "default",
+					Annotations: map[string]string{
+						maxApplicationsAnnotation: "1",
+					},
+				},
+			},
+		},
+		{
+			name:   "namespace annotation can lift the configured quota",
+			quotas: quotas{maxApplications: 1},
+			namespace: corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "default",
+					Annotations: map[string]string{
+						maxApplicationsAnnotation: "0",
+					},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fakeClient := NewFakeClient(t)
+			require.NoError(t, fakeClient.Create(context.Background(), &test.namespace))
+			for _, name := range []string{"app1", "app2"} {
+				err := fakeClient.Create(context.Background(), &appstudiov1alpha1.Application{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      name,
+						Namespace: "default",
+					},
+				})
+				require.NoError(t, err)
+			}
+
+			appWebhook := ApplicationWebhook{
+				admissionConfig: admissionConfig{
+					client: fakeClient,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+				quotas: test.quotas,
+			}
+
+			_, err := appWebhook.ValidateCreate(context.Background(), &appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "app3",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "app3",
+				},
+			})
+
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestApplicationDefaultingWebhook(t *testing.T) {
+	tests := []struct {
+		name            string
+		app             appstudiov1alpha1.Application
+		operation       admissionv1.Operation
+		wantDisplayName string
+		wantDescription string
+		wantLabels      map[string]string
+	}{
+		{
+			name: "display name, description and labels are defaulted",
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "my-app",
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					Description: "  My   application\n\tdescription  ",
+				},
+			},
+			wantDisplayName: "my-app",
+			wantDescription: "My application description",
+			wantLabels: map[string]string{
+				nameLabel:      "my-app",
+				managedByLabel: managedByValue,
+			},
+		},
+		{
+			name: "existing display name and labels are kept",
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "my-app",
+					Labels: map[string]string{
+						managedByLabel: "argocd",
+						"team":         "frontend",
+					},
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					DisplayName: "My App",
+				},
+			},
+			wantDisplayName: "My App",
+			wantLabels: map[string]string{
+				nameLabel:      "my-app",
+				managedByLabel: "argocd",
+				"team":         "frontend",
+			},
+		},
+		{
+			name: "display name and name label are defaulted from generateName",
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					GenerateName: "my-app-",
+				},
+			},
+			wantDisplayName: "my-app",
+			wantLabels: map[string]string{
+				nameLabel:      "my-app",
+				managedByLabel: managedByValue,
+			},
+		},
+		{
+			name: "cleared display name is not defaulted on update",
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "my-app",
+				},
+			},
+			operation: admissionv1.Update,
+			wantLabels: map[string]string{
+				nameLabel:      "my-app",
+				managedByLabel: managedByValue,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			appWebhook := ApplicationWebhook{
+				admissionConfig: admissionConfig{
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+
+			ctx := context.Background()
+			if test.operation != "" {
+				ctx = admission.NewContextWithRequest(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: test.operation}})
+			}
+			err := appWebhook.Default(ctx, &test.app)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantDisplayName, test.app.Spec.DisplayName)
+			assert.Equal(t, test.wantDescription, test.app.Spec.Description)
+			assert.Equal(t, test.wantLabels, test.app.Labels)
+		})
+	}
+}
+
+func TestApplicationDeleteValidatingWebhook(t *testing.T) {
+	fakeClient := NewFakeClient(t)
+	err := fakeClient.Create(context.Background(), &appstudiov1alpha1.Component{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "component1",
+			Namespace: "default",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ComponentName: "component1",
+			Application:   "application-with-components",
+		},
+	})
+	require.NoError(t, err)
+	err = fakeClient.Create(context.Background(), &appstudiov1alpha1.Snapshot{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "snapshot1",
+			Namespace: "default",
+		},
+		Spec: appstudiov1alpha1.SnapshotSpec{
+			Application: "application-with-snapshots",
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		guardDeletion bool
+		app           appstudiov1alpha1.Application
+		err           string
+	}{
+		{
+			name: "deletion guard disabled",
 			err:  "",
-			app:  appstudiov1alpha1.Application{},
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "application-with-components",
+					Namespace: "default",
+				},
+			},
+		},
+		{
+			name:          "application without components or snapshots can be deleted",
+			guardDeletion: true,
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "application-empty",
+					Namespace: "default",
+				},
+			},
+		},
+		{
+			name:          "application with components cannot be deleted",
+			guardDeletion: true,
+			err:           "application application-with-components still owns 1 component(s) and 0 snapshot(s)",
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "application-with-components",
+					Namespace: "default",
+				},
+			},
+		},
+		{
+			name:          "application with snapshots cannot be deleted",
+			guardDeletion: true,
+			err:           "application application-with-snapshots still owns 0 component(s) and 1 snapshot(s)",
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "application-with-snapshots",
+					Namespace: "default",
+				},
+			},
+		},
+		{
+			name:          "application with components and cascade delete annotation can be deleted",
+			guardDeletion: true,
+			app: appstudiov1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "application-with-components",
+					Namespace: "default",
+					Annotations: map[string]string{
+						allowCascadeDeleteAnnotation: "true",
+					},
+				},
+			},
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 
 			appWebhook := ApplicationWebhook{
-				log: zap.New(zap.UseFlagOptions(&zap.Options{
-					Development: true,
-					TimeEncoder: zapcore.ISO8601TimeEncoder,
-				})),
+				admissionConfig: admissionConfig{
+					client: fakeClient,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+				guardDeletion: test.guardDeletion,
 			}
 
-			err := appWebhook.ValidateDelete(context.Background(), &test.app)
+			_, err := appWebhook.ValidateDelete(context.Background(), &test.app)
 
 			if test.err == "" {
 				assert.Nil(t, err)
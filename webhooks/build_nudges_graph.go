@@ -0,0 +1,111 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// buildNudgesGraph is the 'build-nudges-ref' dependency graph of the Components in a namespace
+type buildNudgesGraph struct {
+	// components maps the name of every Component in the namespace to the Component
+	components map[string]*appstudiov1alpha1.Component
+
+	// edges maps the name of a Component to the names of the Components it nudges
+	edges map[string][]string
+}
+
+// newBuildNudgesGraph lists the Components in the namespace once and returns their 'build-nudges-ref' graph.
+// The nudges of the Component being admitted, componentName, replace the ones currently stored in the cluster.
+func newBuildNudgesGraph(ctx context.Context, c client.Client, componentNamespace string, componentName string, nudgedComponentNames []string) (*buildNudgesGraph, error) {
+	var componentList appstudiov1alpha1.ComponentList
+	err := c.List(ctx, &componentList, client.InNamespace(componentNamespace))
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &buildNudgesGraph{
+		components: make(map[string]*appstudiov1alpha1.Component, len(componentList.Items)),
+		edges:      make(map[string][]string, len(componentList.Items)+1),
+	}
+	for i := range componentList.Items {
+		component := &componentList.Items[i]
+		graph.components[component.Name] = component
+		graph.edges[component.Name] = component.Spec.BuildNudgesRef
+	}
+	graph.edges[componentName] = nudgedComponentNames
+
+	return graph, nil
+}
+
+// findCycle runs an iterative depth-first search from the start Component and returns the first cycle it finds, as
+// the list of Component names along the cycle with the first name repeated at the end (e.g. [a b c a]).
+// It returns nil if no cycle is reachable from start. Every Component and edge is visited at most once.
+func (g *buildNudgesGraph) findCycle(start string) []string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+
+	// frame is an entry of the depth-first search stack: a Component and the index of the next edge to follow
+	type frame struct {
+		name     string
+		nextEdge int
+	}
+
+	state := make(map[string]int, len(g.edges))
+	stack := []frame{{name: start}}
+	state[start] = inProgress
+
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		nudged := g.edges[top.name]
+		if top.nextEdge >= len(nudged) {
+			state[top.name] = done
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		next := nudged[top.nextEdge]
+		top.nextEdge++
+
+		switch state[next] {
+		case inProgress:
+			// next is on the current path, so the path from next to the top of the stack is a cycle
+			var cycle []string
+			for i := len(stack) - 1; i >= 0; i-- {
+				cycle = append(cycle, stack[i].name)
+				if stack[i].name == next {
+					break
+				}
+			}
+			for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+				cycle[i], cycle[j] = cycle[j], cycle[i]
+			}
+			return append(cycle, next)
+		case unvisited:
+			state[next] = inProgress
+			stack = append(stack, frame{name: next})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// validateComponentRuntime returns the violations found in the replicas, target port, resources, env and route of
+// newSpec. If oldSpec is set, only the fields that changed since oldSpec are validated, as for the git source.
+func validateComponentRuntime(newSpec, oldSpec *appstudiov1alpha1.ComponentSpec, fldPath *field.Path) field.ErrorList {
+	if oldSpec == nil {
+		oldSpec = &appstudiov1alpha1.ComponentSpec{}
+	}
+	var errs field.ErrorList
+
+	if newSpec.Replicas != nil && !reflect.DeepEqual(newSpec.Replicas, oldSpec.Replicas) && *newSpec.Replicas < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("replicas"), *newSpec.Replicas, "must be greater than or equal to 0"))
+	}
+
+	if newSpec.TargetPort != 0 && newSpec.TargetPort != oldSpec.TargetPort {
+		for _, msg := range validation.IsValidPortNum(newSpec.TargetPort) {
+			errs = append(errs, field.Invalid(fldPath.Child("targetPort"), newSpec.TargetPort, msg))
+		}
+	}
+
+	if !reflect.DeepEqual(newSpec.Resources, oldSpec.Resources) {
+		errs = append(errs, validateResources(newSpec.Resources, fldPath.Child("resources"))...)
+	}
+
+	if !reflect.DeepEqual(newSpec.Env, oldSpec.Env) {
+		errs = append(errs, validateEnv(newSpec.Env, fldPath.Child("env"))...)
+	}
+
+	if newSpec.Route != "" && newSpec.Route != oldSpec.Route {
+		for _, msg := range validation.IsDNS1123Subdomain(newSpec.Route) {
+			errs = append(errs, field.Invalid(fldPath.Child("route"), newSpec.Route, msg))
+		}
+	}
+
+	return errs
+}
+
+// validateResources returns an error for every negative quantity and for every request larger than its limit
+func validateResources(resources corev1.ResourceRequirements, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	for _, name := range sortedResourceNames(resources.Limits) {
+		if quantity := resources.Limits[name]; quantity.Sign() < 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("limits").Key(string(name)), quantity.String(), "must be greater than or equal to 0"))
+		}
+	}
+
+	for _, name := range sortedResourceNames(resources.Requests) {
+		request := resources.Requests[name]
+		if request.Sign() < 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("requests").Key(string(name)), request.String(), "must be greater than or equal to 0"))
+			continue
+		}
+		if limit, ok := resources.Limits[name]; ok && request.Cmp(limit) > 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("requests").Key(string(name)), request.String(), fmt.Sprintf("must be less than or equal to %s limit of %s", name, limit.String())))
+		}
+	}
+
+	return errs
+}
+
+// validateEnv returns an error for every invalid or duplicate environment variable name
+func validateEnv(env []corev1.EnvVar, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	seen := map[string]bool{}
+	for i, envVar := range env {
+		namePath := fldPath.Index(i).Child("name")
+		for _, msg := range validation.IsEnvVarName(envVar.Name) {
+			errs = append(errs, field.Invalid(namePath, envVar.Name, msg))
+		}
+		if seen[envVar.Name] {
+			errs = append(errs, field.Duplicate(namePath, envVar.Name))
+		}
+		seen[envVar.Name] = true
+	}
+
+	return errs
+}
+
+// sortedResourceNames returns the names of the given resources in alphabetical order, so that the errors are reported
+// in a stable order
+func sortedResourceNames(resources corev1.ResourceList) []corev1.ResourceName {
+	var names []corev1.ResourceName
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
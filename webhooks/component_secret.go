@@ -0,0 +1,69 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// tokenSecretKey is the key holding the access token in Opaque git Secrets
+const tokenSecretKey = "token"
+
+// gitSecretKeys maps the supported types of git Secrets to the keys they must contain:
+// basic-auth Secrets, e.g. the ones created by SPI, hold the token in their password, ssh-auth Secrets hold a private key
+// and Opaque Secrets hold a token
+var gitSecretKeys = map[corev1.SecretType]string{
+	corev1.SecretTypeBasicAuth: corev1.BasicAuthPasswordKey,
+	corev1.SecretTypeSSHAuth:   corev1.SSHAuthPrivateKey,
+	corev1.SecretTypeOpaque:    tokenSecretKey,
+}
+
+// validateSecret returns a violation if the Secret the Component uses to access its private git repository doesn't
+// have a supported type or misses the key of its type. A missing Secret is only reported as a warning, so that it can
+// be created after the Component. The Secret is read from the API server, so that the webhook doesn't cache the
+// Secrets of the cluster.
+func (r *ComponentWebhook) validateSecret(ctx context.Context, comp *appstudiov1alpha1.Component, fldPath *field.Path) (admission.Warnings, field.ErrorList) {
+	componentlog := requestLogger(ctx, r.log, "Component", comp)
+
+	var secret corev1.Secret
+	err := r.reader().Get(ctx, types.NamespacedName{Namespace: comp.Namespace, Name: comp.Spec.Secret}, &secret)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return admission.Warnings{fmt.Sprintf("secret %s does not exist yet, component %s will not be able to access its git repository until it is created", comp.Spec.Secret, comp.Name)}, nil
+		}
+		componentlog.Error(err, "unable to get the Secret of the Component, skipping the check")
+		return nil, nil
+	}
+
+	key, ok := gitSecretKeys[secret.Type]
+	if !ok {
+		return nil, field.ErrorList{field.Invalid(fldPath, comp.Spec.Secret, fmt.Sprintf("secret %s has type %s, supported types are: %s", comp.Spec.Secret, secret.Type, strings.Join([]string{string(corev1.SecretTypeBasicAuth), string(corev1.SecretTypeSSHAuth), string(corev1.SecretTypeOpaque)}, ", ")))}
+	}
+	if len(secret.Data[key]) == 0 {
+		return nil, field.ErrorList{field.Invalid(fldPath, comp.Spec.Secret, fmt.Sprintf("secret %s of type %s must contain the %s key", comp.Spec.Secret, secret.Type, key))}
+	}
+	return nil, nil
+}
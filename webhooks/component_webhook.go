@@ -18,33 +18,57 @@ package webhooks
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
 
 	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/redhat-appstudio/application-service/pkg/util"
 
 	"github.com/go-logr/logr"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
-	"k8s.io/client-go/util/retry"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // log is for logging in this package.
 // Webhook describes the data structure for the release webhook
 type ComponentWebhook struct {
-	client client.Client
-	log    logr.Logger
+	admissionConfig
+
+	// warnOnCrossApplicationNudges allows build-nudges-ref entries targeting a Component of another Application,
+	// logging a warning instead of rejecting the request
+	warnOnCrossApplicationNudges bool
+
+	// quotas are the default limits on the number of Components per namespace and per Application
+	quotas quotas
+
+	// allowedGitHosts are the hosts git sources can be hosted on. If empty, any public host is allowed.
+	allowedGitHosts []string
+
+	// allowedImageRegistries are the registries container images can be hosted in. If empty, any registry is allowed.
+	allowedImageRegistries []string
+
+	// requireImageDigestInProduction requires the container images of the Components of production Applications
+	// to be referenced by digest
+	requireImageDigestInProduction bool
 }
 
 func (w *ComponentWebhook) Register(mgr ctrl.Manager, log *logr.Logger) error {
-	w.client = mgr.GetClient()
+	if err := w.register(mgr, log, componentWebhookName); err != nil {
+		return err
+	}
+	w.warnOnCrossApplicationNudges = os.Getenv("BUILD_NUDGES_CROSS_APPLICATION") == "warn"
+	w.quotas = quotasFromEnv(w.log)
+	w.allowedGitHosts = allowedGitHostsFromEnv()
+	w.allowedImageRegistries = allowedImageRegistriesFromEnv()
+	w.requireImageDigestInProduction = os.Getenv("REQUIRE_IMAGE_DIGEST_IN_PRODUCTION") == "true"
 
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&appstudiov1alpha1.Component{}).
@@ -58,245 +82,242 @@ func (w *ComponentWebhook) Register(mgr ctrl.Manager, log *logr.Logger) error {
 // +kubebuilder:webhook:path=/mutate-appstudio-redhat-com-v1alpha1-component,mutating=true,failurePolicy=fail,sideEffects=None,groups=appstudio.redhat.com,resources=components;components/status,verbs=create;update,versions=v1alpha1,name=mcomponent.kb.io,admissionReviewVersions=v1
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
+// It normalizes the URL of the Component's git source, so that equivalent URLs of a repository are stored identically.
+// The Component's owner reference to its Application is set by the ApplicationOwnershipReconciler, and its
+// status.build-nudged-by field by the BuildNudgesReconciler, so that the webhooks don't write to other objects. The only
+// side effects of the validating webhook are the events it records, which are skipped on dry-run requests, as declared
+// by sideEffects=NoneOnDryRun.
 func (r *ComponentWebhook) Default(ctx context.Context, obj runtime.Object) error {
-	component := obj.(*appstudiov1alpha1.Component)
-	compName := component.Name
-	componentlog := r.log.WithValues("controllerKind", "Component").WithValues("name", compName).WithValues("namespace", component.Namespace)
-
-	if len(component.OwnerReferences) == 0 && component.DeletionTimestamp.IsZero() {
-		// Get the Application CR
-		// Use the background context to ensure the operator's kubeconfig is used
-		hasApplication := appstudiov1alpha1.Application{}
-		err := r.client.Get(context.Background(), types.NamespacedName{Name: component.Spec.Application, Namespace: component.Namespace}, &hasApplication)
-		if err != nil {
-			// Don't block if the Application doesn't exist yet - this will retrigger whenever the resource is modified
-			err = fmt.Errorf("unable to get the Application %s for Component %s, ignoring for now", component.Spec.Application, compName)
-			componentlog.Error(err, "skip setting owner reference on component")
-		} else {
-			// Update the Component's owner ref's - retry on conflict
-			err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-				var curComp appstudiov1alpha1.Component
-				// Get the Component to update using the operator's kubeconfig so that there aren't any permissions issues setting the owner reference
-				// Use the background context to ensure the operator's kubeconfig is used
-				err := r.client.Get(context.Background(), types.NamespacedName{Name: compName, Namespace: component.Namespace}, &curComp)
-				if err != nil {
-					componentlog.Error(err, "unable to get current component, so skip setting owner reference")
-					return nil
-				}
-
-				ownerReference := metav1.OwnerReference{
-					APIVersion: hasApplication.APIVersion,
-					Kind:       hasApplication.Kind,
-					Name:       hasApplication.Name,
-					UID:        hasApplication.UID,
-				}
-				curComp.SetOwnerReferences(append(curComp.GetOwnerReferences(), ownerReference))
-				err = r.client.Update(ctx, &curComp)
-				return err
-			})
-			if err != nil {
-				componentlog.Error(err, "error setting owner-references")
-			}
-
-		}
-	}
-
-	return nil
-}
-
-// UpdateNudgedComponentStatus retrieves the list of components that the Component nudges and updates their statuses to list
-// the component as a nudging component (status.BuildNudgedBy)
-func (r *ComponentWebhook) UpdateNudgedComponentStatus(ctx context.Context, obj runtime.Object) error {
 	comp := obj.(*appstudiov1alpha1.Component)
-	compName := comp.Name
-	componentlog := r.log.WithValues("controllerKind", "Component").WithValues("name", compName).WithValues("namespace", comp.Namespace)
-
-	// For each component that the Component nudges, retrieve its resource and update its status accordingly
-	for _, nudgedCompName := range comp.Spec.BuildNudgesRef {
-		// Retrieved the nudged component
-		nudgedComp := &appstudiov1alpha1.Component{}
-		err := r.client.Get(ctx, types.NamespacedName{Namespace: comp.Namespace, Name: nudgedCompName}, nudgedComp)
-		if err != nil {
-			// Return an error if an error was encountered retrieving the resource.
-			// If the resource wasn't found yet - leave it however
-			if !k8sErrors.IsNotFound(err) {
-				return err
-			} else {
-				componentlog.Error(err, "nudged component not found, skip setting the status for now")
-				continue
-			}
-		}
-
-		// Add the component to the status if it's not already present
-		if !util.StrInList(compName, nudgedComp.Status.BuildNudgedBy) {
-
-			// Update the Component's status - retry on conflict
-			err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-				currentNudgedComp := &appstudiov1alpha1.Component{}
-				err := r.client.Get(ctx, types.NamespacedName{Namespace: comp.Namespace, Name: nudgedCompName}, currentNudgedComp)
-				if err != nil {
-					return err
-				}
-				currentNudgedComp.Status.BuildNudgedBy = append(currentNudgedComp.Status.BuildNudgedBy, compName)
-				err = r.client.Status().Update(ctx, currentNudgedComp)
-				return err
-			})
-			if err != nil {
-				componentlog.Error(err, "error setting build-nudged-by in status")
-			}
-
-		}
 
+	if comp.Spec.Source.GitSource != nil && comp.Spec.Source.GitSource.URL != "" {
+		comp.Spec.Source.GitSource.URL = normalizeGitRepositoryURL(comp.Spec.Source.GitSource.URL)
 	}
 	return nil
 }
 
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
-// +kubebuilder:webhook:path=/validate-appstudio-redhat-com-v1alpha1-component,mutating=false,failurePolicy=fail,sideEffects=None,groups=appstudio.redhat.com,resources=components,verbs=create;update,versions=v1alpha1,name=vcomponent.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-appstudio-redhat-com-v1alpha1-component,mutating=false,failurePolicy=fail,sideEffects=NoneOnDryRun,groups=appstudio.redhat.com,resources=components,verbs=create;update,versions=v1alpha1,name=vcomponent.kb.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *ComponentWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+func (r *ComponentWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(componentWebhookName, createOperation, time.Now(), &err)
 	comp := obj.(*appstudiov1alpha1.Component)
-	componentlog := r.log.WithValues("controllerKind", "Component").WithValues("name", comp.Name).WithValues("namespace", comp.Namespace)
+	componentlog := requestLogger(ctx, r.log, "Component", comp)
 	componentlog.Info("validating the create request")
 
+	var errs field.ErrorList
+	var warnings admission.Warnings
+	specPath := field.NewPath("spec")
+
 	// We use the DNS-1035 format for component names, so ensure it conforms to that specification
 	if len(validation.IsDNS1035Label(comp.Name)) != 0 {
-		return fmt.Errorf(appstudiov1alpha1.InvalidDNS1035Name, comp.Name)
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), comp.Name, fmt.Sprintf(appstudiov1alpha1.InvalidDNS1035Name, comp.Name)))
 	}
-	sourceSpecified := false
 
-	if comp.Spec.Source.GitSource != nil && comp.Spec.Source.GitSource.URL != "" {
-		if _, err := url.ParseRequestURI(comp.Spec.Source.GitSource.URL); err != nil {
-			return fmt.Errorf(err.Error() + appstudiov1alpha1.InvalidSchemeGitSourceURL)
+	gitSource := comp.Spec.Source.GitSource
+	if gitSource != nil && gitSource.URL != "" {
+		gitPath := specPath.Child("source", "git")
+		errs = append(errs, validateGitSourceURL(gitSource.URL, r.allowedGitHosts, gitPath.Child("url"))...)
+		errs = append(errs, validateGitSource(gitSource, nil, gitPath)...)
+		if gitSource.Revision == "" {
+			warnings = append(warnings, fmt.Sprintf("git source %s does not specify a revision, the default branch of the repository will be used", gitSource.URL))
 		}
-		sourceSpecified = true
-	} else if comp.Spec.ContainerImage != "" {
-		sourceSpecified = true
+	} else if comp.Spec.ContainerImage == "" {
+		errs = append(errs, field.Required(specPath.Child("source"), appstudiov1alpha1.MissingGitOrImageSource))
 	}
 
-	if !sourceSpecified {
-		return errors.New(appstudiov1alpha1.MissingGitOrImageSource)
+	if comp.Spec.ContainerImage != "" {
+		errs = append(errs, r.validateContainerImage(ctx, comp, specPath.Child("containerImage"))...)
+	}
+
+	errs = append(errs, validateComponentRuntime(&comp.Spec, nil, specPath)...)
+
+	if comp.Spec.Secret != "" {
+		secretWarnings, secretErrs := r.validateSecret(ctx, comp, specPath.Child("secret"))
+		warnings = append(warnings, secretWarnings...)
+		errs = append(errs, secretErrs...)
+	}
+
+	if comp.Spec.Application != "" {
+		warnings = append(warnings, r.validateApplicationExists(ctx, comp)...)
 	}
 
 	if len(comp.Spec.BuildNudgesRef) != 0 {
-		err := r.validateBuildNudgesRefGraph(ctx, comp.Spec.BuildNudgesRef, comp.Namespace, comp.Name)
+		nudgeWarnings, nudgeErrs, err := r.validateBuildNudgesRef(ctx, comp, specPath.Child("build-nudges-ref"))
 		if err != nil {
-			return err
-		}
-		err = r.UpdateNudgedComponentStatus(ctx, comp)
-		if err != nil {
-			return err
+			return nil, err
 		}
+		warnings = append(warnings, nudgeWarnings...)
+		errs = append(errs, nudgeErrs...)
 	}
 
-	return nil
+	return r.finish(ctx, componentlog, "Component", comp, nil, errs, warnings, func() error {
+		return r.quotas.forNamespace(ctx, r.reader(), componentlog, comp.Namespace).validateComponentQuota(ctx, r.client, comp.Namespace, comp.Spec.Application)
+	})
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *ComponentWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+func (r *ComponentWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(componentWebhookName, updateOperation, time.Now(), &err)
 	oldComp := oldObj.(*appstudiov1alpha1.Component)
 	newComp := newObj.(*appstudiov1alpha1.Component)
 
-	componentlog := r.log.WithValues("controllerKind", "Component").WithValues("name", newComp.Name).WithValues("namespace", newComp.Namespace)
+	componentlog := requestLogger(ctx, r.log, "Component", newComp)
 	componentlog.Info("validating the update request")
 
+	var errs field.ErrorList
+	var warnings admission.Warnings
+	specPath := field.NewPath("spec")
+
 	if newComp.Spec.ComponentName != oldComp.Spec.ComponentName {
-		return fmt.Errorf(appstudiov1alpha1.ComponentNameUpdateError, newComp.Spec.ComponentName)
+		errs = append(errs, field.Invalid(specPath.Child("componentName"), newComp.Spec.ComponentName, fmt.Sprintf(appstudiov1alpha1.ComponentNameUpdateError, newComp.Spec.ComponentName)))
 	}
 
 	if newComp.Spec.Application != oldComp.Spec.Application {
-		return fmt.Errorf(appstudiov1alpha1.ApplicationNameUpdateError, newComp.Spec.Application)
+		errs = append(errs, field.Invalid(specPath.Child("application"), newComp.Spec.Application, fmt.Sprintf(appstudiov1alpha1.ApplicationNameUpdateError, newComp.Spec.Application)))
 	}
 
-	if newComp.Spec.Source.GitSource != nil && oldComp.Spec.Source.GitSource != nil && (newComp.Spec.Source.GitSource.URL != oldComp.Spec.Source.GitSource.URL) {
-		return fmt.Errorf(appstudiov1alpha1.GitSourceUpdateError, *(newComp.Spec.Source.GitSource))
-	}
-	if len(newComp.Spec.BuildNudgesRef) != 0 {
-		err := r.validateBuildNudgesRefGraph(ctx, newComp.Spec.BuildNudgesRef, newComp.Namespace, newComp.Name)
-		if err != nil {
-			return err
+	if newComp.Spec.Source.GitSource != nil {
+		gitPath := specPath.Child("source", "git")
+		if oldComp.Spec.Source.GitSource != nil && (canonicalGitRepositoryURL(newComp.Spec.Source.GitSource.URL) != canonicalGitRepositoryURL(oldComp.Spec.Source.GitSource.URL)) {
+			errs = append(errs, field.Invalid(gitPath.Child("url"), newComp.Spec.Source.GitSource.URL, fmt.Sprintf(appstudiov1alpha1.GitSourceUpdateError, *(newComp.Spec.Source.GitSource))))
 		}
-
-		// If the dependency graph was successfully validated, update the statuses of the Components
-		err = r.UpdateNudgedComponentStatus(ctx, newComp)
+		errs = append(errs, validateGitSource(newComp.Spec.Source.GitSource, oldComp.Spec.Source.GitSource, gitPath)...)
+	}
+	if newComp.Spec.ContainerImage != "" && newComp.Spec.ContainerImage != oldComp.Spec.ContainerImage {
+		errs = append(errs, r.validateContainerImage(ctx, newComp, specPath.Child("containerImage"))...)
+	}
+	errs = append(errs, validateComponentRuntime(&newComp.Spec, &oldComp.Spec, specPath)...)
+	if newComp.Spec.Secret != "" && newComp.Spec.Secret != oldComp.Spec.Secret {
+		secretWarnings, secretErrs := r.validateSecret(ctx, newComp, specPath.Child("secret"))
+		warnings = append(warnings, secretWarnings...)
+		errs = append(errs, secretErrs...)
+	}
+	// As for the other fields, unchanged build-nudges-ref entries aren't revalidated, so that a Component whose nudges
+	// were valid when they were set can still be updated, e.g. by the ApplicationOwnershipReconciler
+	if len(newComp.Spec.BuildNudgesRef) != 0 && !reflect.DeepEqual(newComp.Spec.BuildNudgesRef, oldComp.Spec.BuildNudgesRef) {
+		nudgeWarnings, nudgeErrs, err := r.validateBuildNudgesRef(ctx, newComp, specPath.Child("build-nudges-ref"))
 		if err != nil {
-			return err
+			return nil, err
 		}
+		warnings = append(warnings, nudgeWarnings...)
+		errs = append(errs, nudgeErrs...)
 	}
 
-	return nil
+	return r.finish(ctx, componentlog, "Component", newComp, oldComp, errs, warnings, nil)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *ComponentWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
-	comp := obj.(*appstudiov1alpha1.Component)
-	compName := comp.Name
-	componentNamespace := comp.Namespace
-	componentlog := r.log.WithValues("controllerKind", "Component").WithValues("name", compName).WithValues("namespace", comp.Namespace)
-
-	// Check which Components this component nudges. Update their statuses to remove the component
-	for _, nudgedComponentName := range comp.Spec.BuildNudgesRef {
-		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			nudgedComponent := &appstudiov1alpha1.Component{}
-			err := r.client.Get(ctx, types.NamespacedName{Namespace: componentNamespace, Name: nudgedComponentName}, nudgedComponent)
-			if err != nil {
-				return err
-			}
-			nudgedComponent.Status.BuildNudgedBy = util.RemoveStrFromList(compName, nudgedComponent.Status.BuildNudgedBy)
-			err = r.client.Status().Update(ctx, nudgedComponent)
-			return err
-		})
+// The status.build-nudged-by field of the Components nudged by the deleted Component is cleaned up by the BuildNudgesReconciler
+func (r *ComponentWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(componentWebhookName, deleteOperation, time.Now(), &err)
+	return nil, nil
+}
 
-		if err != nil {
-			// Don't block component deletion if this fails, but log and continue
-			componentlog.Error(err, "error deleting component name from build-nudges-ref")
-		}
+// validateApplicationExists returns a warning if the Application the Component belongs to does not exist yet.
+// A missing Application doesn't block the request: the Component is adopted by its Application once it's created.
+func (r *ComponentWebhook) validateApplicationExists(ctx context.Context, comp *appstudiov1alpha1.Component) admission.Warnings {
+	componentlog := requestLogger(ctx, r.log, "Component", comp)
 
+	var application appstudiov1alpha1.Application
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: comp.Namespace, Name: comp.Spec.Application}, &application)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return admission.Warnings{fmt.Sprintf("application %s does not exist yet, component %s will be added to it once it is created", comp.Spec.Application, comp.Name)}
+		}
+		componentlog.Error(err, "unable to get the Application of the Component, skipping the check")
 	}
+	return nil
+}
 
-	// Next, loop through the Component's list of nudging components, and update their specs
-	for _, nudgedComponentName := range comp.Status.BuildNudgedBy {
-		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			nudgingComponent := &appstudiov1alpha1.Component{}
-			err := r.client.Get(ctx, types.NamespacedName{Namespace: componentNamespace, Name: nudgedComponentName}, nudgingComponent)
-			if err != nil {
-				return err
-			}
-			nudgingComponent.Spec.BuildNudgesRef = util.RemoveStrFromList(compName, nudgingComponent.Spec.BuildNudgesRef)
-			err = r.client.Update(ctx, nudgingComponent)
-			return err
-		})
-		if err != nil {
-			// Don't block component deletion if this fails, but log and continue
-			componentlog.Error(err, "error deleting component name from build-nudges-ref")
-		}
+// validateContainerImage returns a violation if the Component's container image isn't a valid image reference from an
+// allowed registry, or if it isn't referenced by digest while the Component belongs to a production Application
+func (r *ComponentWebhook) validateContainerImage(ctx context.Context, comp *appstudiov1alpha1.Component, fldPath *field.Path) field.ErrorList {
+	requireDigest := r.requireImageDigestInProduction && r.isProductionApplication(ctx, comp)
+	if err := validateContainerImage(comp.Spec.ContainerImage, r.allowedImageRegistries, requireDigest); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, comp.Spec.ContainerImage, err.Error())}
 	}
 	return nil
 }
 
-// validateBuildNudgesRefGraph returns an error if a cycle was found in the 'build-nudges-ref' dependency graph
-// If no cycle is found, it returns nil
-func (r *ComponentWebhook) validateBuildNudgesRefGraph(ctx context.Context, nudgedComponentNames []string, componentNamespace string, componentName string) error {
-	for _, nudgedComponentName := range nudgedComponentNames {
-		if nudgedComponentName == componentName {
-			return fmt.Errorf("cycle detected: component %s cannot reference itself, directly or indirectly, via build-nudges-ref", nudgedComponentName)
+// isProductionApplication returns true if the Application the Component belongs to is labelled as a production one.
+// It returns false if the Application can't be retrieved.
+func (r *ComponentWebhook) isProductionApplication(ctx context.Context, comp *appstudiov1alpha1.Component) bool {
+	if comp.Spec.Application == "" {
+		return false
+	}
+	var application appstudiov1alpha1.Application
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: comp.Namespace, Name: comp.Spec.Application}, &application)
+	if err != nil {
+		return false
+	}
+	return application.Labels[environmentLabel] == productionEnvironment
+}
+
+// validateBuildNudgesRef returns a violation for every 'build-nudges-ref' entry of the Component that references the
+// Component itself or is a duplicate. If there is none, it validates the dependency graph of the entries.
+// The returned error is only set if the Components couldn't be listed
+func (r *ComponentWebhook) validateBuildNudgesRef(ctx context.Context, comp *appstudiov1alpha1.Component, fldPath *field.Path) (admission.Warnings, field.ErrorList, error) {
+	var errs field.ErrorList
+	var seen []string
+	for i, nudgedComponentName := range comp.Spec.BuildNudgesRef {
+		if nudgedComponentName == comp.Name {
+			errs = append(errs, field.Invalid(fldPath.Index(i), nudgedComponentName, fmt.Sprintf("component %s cannot nudge itself via build-nudges-ref", comp.Name)))
+		} else if util.StrInList(nudgedComponentName, seen) {
+			errs = append(errs, field.Duplicate(fldPath.Index(i), nudgedComponentName))
 		}
+		seen = append(seen, nudgedComponentName)
+	}
+	if len(errs) != 0 {
+		return nil, errs, nil
+	}
 
-		nudgedComponent := &appstudiov1alpha1.Component{}
-		err := r.client.Get(ctx, types.NamespacedName{Namespace: componentNamespace, Name: nudgedComponentName}, nudgedComponent)
-		if err != nil {
-			// Return an error if an error was encountered retrieving the resource
-			if !k8sErrors.IsNotFound(err) {
-				return err
+	return r.validateBuildNudgesRefGraph(ctx, comp.Spec.BuildNudgesRef, comp.Namespace, comp.Name, comp.Spec.Application, fldPath)
+}
+
+// validateBuildNudgesRefGraph returns a violation if a cycle was found in the 'build-nudges-ref' dependency graph, or
+// for every nudged Component that belongs to a different Application than applicationName
+// The cycle violation names the Components along the cycle. The returned error is only set if the Components couldn't
+// be listed
+// Nudged Components that don't exist yet, and cross-Application nudges when they are allowed, are returned as warnings
+func (r *ComponentWebhook) validateBuildNudgesRefGraph(ctx context.Context, nudgedComponentNames []string, componentNamespace string, componentName string, applicationName string, fldPath *field.Path) (admission.Warnings, field.ErrorList, error) {
+	graph, err := newBuildNudgesGraph(ctx, r.client, componentNamespace, componentName, nudgedComponentNames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cycle := graph.findCycle(componentName); cycle != nil {
+		detail := fmt.Sprintf("cycle detected in build-nudges-ref: %s", strings.Join(cycle, " -> "))
+		// Point at the entry the cycle goes through when the cycle starts from the admitted Component
+		for i, nudgedComponentName := range nudgedComponentNames {
+			if cycle[0] == componentName && nudgedComponentName == cycle[1] {
+				return nil, field.ErrorList{field.Invalid(fldPath.Index(i), nudgedComponentName, detail)}, nil
 			}
 		}
+		return nil, field.ErrorList{field.Invalid(fldPath, nudgedComponentNames, detail)}, nil
+	}
 
-		err = r.validateBuildNudgesRefGraph(ctx, nudgedComponent.Spec.BuildNudgesRef, componentNamespace, componentName)
-		if err != nil {
-			return err
+	var warnings admission.Warnings
+	var errs field.ErrorList
+	for i, nudgedComponentName := range nudgedComponentNames {
+		nudgedComponent, ok := graph.components[nudgedComponentName]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("component %s nudged via build-nudges-ref does not exist yet", nudgedComponentName))
+			continue
+		}
+		if nudgedComponent.Spec.Application == applicationName {
+			continue
 		}
+		detail := fmt.Sprintf("component %s cannot nudge component %s via build-nudges-ref: it belongs to application %s instead of %s", componentName, nudgedComponentName, nudgedComponent.Spec.Application, applicationName)
+		if r.warnOnCrossApplicationNudges {
+			warnings = append(warnings, detail)
+			continue
+		}
+		errs = append(errs, field.Invalid(fldPath.Index(i), nudgedComponentName, detail))
 	}
 
-	return nil
+	return warnings, errs, nil
 }
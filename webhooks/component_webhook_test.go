@@ -17,7 +17,6 @@ package webhooks
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"reflect"
 
@@ -26,8 +25,10 @@ import (
 
 	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
 
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -92,7 +93,7 @@ var _ = Describe("Application validation webhook", func() {
 			hasComp.Spec.Source.GitSource.URL = "badurl"
 			err = k8sClient.Create(ctx, hasComp)
 			Expect(err).Should(HaveOccurred())
-			Expect(err.Error()).Should(ContainSubstring(errors.New("invalid URI for request" + appstudiov1alpha1.InvalidSchemeGitSourceURL).Error()))
+			Expect(err.Error()).Should(ContainSubstring("spec.source.git.url: Invalid value: \"badurl\""))
 
 			// Good URL
 			hasComp.Spec.Source.GitSource.URL = SampleRepoLink
@@ -234,7 +235,7 @@ var _ = Describe("Application validation webhook", func() {
 
 			err = k8sClient.Create(ctx, nudgingComp)
 			Expect(err).Should((HaveOccurred()))
-			Expect(err.Error()).Should(ContainSubstring("cycle detected"))
+			Expect(err.Error()).Should(ContainSubstring(fmt.Sprintf("component %s cannot nudge itself via build-nudges-ref", uniqueHASCompName)))
 
 			// After changing to a valid build nudges ref, create should succeed
 			nudgingComp.Spec.BuildNudgesRef = []string{uniqueHASCompName + "-nudge"}
@@ -265,6 +266,78 @@ var _ = Describe("Application validation webhook", func() {
 		})
 	})
 
+	Context("Create Component CR with build-nudges-ref in dry-run mode", func() {
+		It("Should leave the nudged Component untouched", func() {
+			ctx := context.Background()
+
+			uniqueHASCompName := HASCompName + "4"
+
+			nudgedComp := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: HASAppNamespace,
+					Name:      uniqueHASCompName + "-nudge",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: uniqueHASCompName + "-nudge",
+					Application:   "test-application",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, nudgedComp)).Should(Succeed())
+			resourceVersion := nudgedComp.ResourceVersion
+
+			nudgingComp := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: HASAppNamespace,
+					Name:      uniqueHASCompName,
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  uniqueHASCompName,
+					Application:    "test-application",
+					BuildNudgesRef: []string{uniqueHASCompName + "-nudge"},
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, nudgingComp, client.DryRunAll)).Should(Succeed())
+
+			// The nudging component was not persisted
+			hasCompLookupKey := types.NamespacedName{Name: uniqueHASCompName, Namespace: HASAppNamespace}
+			err := k8sClient.Get(ctx, hasCompLookupKey, &appstudiov1alpha1.Component{})
+			Expect(k8sErrors.IsNotFound(err)).Should(BeTrue())
+
+			// Look up the nudged component and verify that it was not modified
+			nudgedCompLookupKey := types.NamespacedName{Name: uniqueHASCompName + "-nudge", Namespace: HASAppNamespace}
+			Consistently(func() bool {
+				nudgedComp = &appstudiov1alpha1.Component{}
+				k8sClient.Get(ctx, nudgedCompLookupKey, nudgedComp)
+				return nudgedComp.ResourceVersion == resourceVersion && len(nudgedComp.Status.BuildNudgedBy) == 0
+			}, duration, interval).Should(BeTrue())
+
+			// Delete the specified HASComp resource
+			deleteHASCompCR(nudgedCompLookupKey)
+		})
+	})
+
 })
 
 // deleteHASCompCR deletes the specified hasComp resource and verifies it was properly deleted
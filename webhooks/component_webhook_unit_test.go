@@ -18,21 +18,32 @@ package webhooks
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
-	"github.com/redhat-appstudio/application-service/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/redhat-appstudio/application-service/pkg/policy"
 	"go.uber.org/zap/zapcore"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 )
 
 func TestComponentDefaultingWebhook(t *testing.T) {
@@ -95,29 +106,86 @@ func TestComponentDefaultingWebhook(t *testing.T) {
 			err := test.client.Create(context.Background(), &test.comp)
 			assert.Nil(t, err)
 			compWebhook := ComponentWebhook{
-				client: test.client,
-				log: zap.New(zap.UseFlagOptions(&zap.Options{
-					Development: true,
-					TimeEncoder: zapcore.ISO8601TimeEncoder,
-				})),
+				admissionConfig: admissionConfig{
+					client: test.client,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
 			}
 			err = compWebhook.Default(context.Background(), &test.comp)
 
 			// Defaulting webhook should not return an error
 			assert.Nil(t, err)
 
-			// Ensure that the component had its owner reference set correctly
-			// Get the updated component
+			// The owner reference is set by the ApplicationOwnershipReconciler, the webhook must not update the component
 			var updatedComp appstudiov1alpha1.Component
-			test.client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: test.comp.Name}, &updatedComp)
+			err = test.client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: test.comp.Name}, &updatedComp)
+			require.NoError(t, err)
+			assert.Equal(t, test.comp.ResourceVersion, updatedComp.ResourceVersion)
+			assert.Empty(t, updatedComp.OwnerReferences)
+		})
+	}
+}
 
-			if len(updatedComp.OwnerReferences) != 1 && test.name != "application not found" {
-				t.Error("expected component to have owner reference set")
-			} else if test.name != "application not found" {
-				if updatedComp.OwnerReferences[0].Name != "application1" {
-					t.Errorf("expected component to have owner reference set to application %s, got %s", "application1", test.comp.OwnerReferences[0].Name)
-				}
+func TestComponentDefaultingWebhookGitURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantURL string
+	}{
+		{
+			name:    "normalized url is unchanged",
+			url:     "https://github.com/devfile-samples/devfile-sample-python-basic",
+			wantURL: "https://github.com/devfile-samples/devfile-sample-python-basic",
+		},
+		{
+			name:    ".git suffix is stripped",
+			url:     "https://github.com/devfile-samples/devfile-sample-python-basic.git",
+			wantURL: "https://github.com/devfile-samples/devfile-sample-python-basic",
+		},
+		{
+			name:    "trailing slashes are stripped",
+			url:     "https://github.com/devfile-samples/devfile-sample-python-basic.git//",
+			wantURL: "https://github.com/devfile-samples/devfile-sample-python-basic",
+		},
+		{
+			name:    "www prefix is stripped and host is lower cased",
+			url:     "https://WWW.GitHub.com/devfile-samples/devfile-sample-python-basic",
+			wantURL: "https://github.com/devfile-samples/devfile-sample-python-basic",
+		},
+		{
+			name:    "invalid url is left as is",
+			url:     "git@github.com:devfile-samples/devfile-sample-python-basic.git",
+			wantURL: "git@github.com:devfile-samples/devfile-sample-python-basic.git",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+			comp := appstudiov1alpha1.Component{
+				Spec: appstudiov1alpha1.ComponentSpec{
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: test.url,
+							},
+						},
+					},
+				},
 			}
+
+			err := compWebhook.Default(context.Background(), &comp)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantURL, comp.Spec.Source.GitSource.URL)
 		})
 	}
 }
@@ -150,7 +218,7 @@ func TestComponentCreateValidatingWebhook(t *testing.T) {
 		{
 			name:   "component cannot be created due to bad URL",
 			client: fakeClient,
-			err:    "invalid URI for request" + appstudiov1alpha1.InvalidSchemeGitSourceURL,
+			err:    "spec.source.git.url: Invalid value: \"badurl\": \"badurl\" is not an http(s), ssh or scp-like git URL",
 			newComp: appstudiov1alpha1.Component{
 				ObjectMeta: v1.ObjectMeta{
 					Name: "test-component",
@@ -188,9 +256,8 @@ func TestComponentCreateValidatingWebhook(t *testing.T) {
 			},
 		},
 		{
-			name:   "valid component with invalid git scheme src",
+			name:   "valid component with scp-like git src",
 			client: fakeClient,
-			err:    "invalid URI for request" + appstudiov1alpha1.InvalidSchemeGitSourceURL,
 			newComp: appstudiov1alpha1.Component{
 				ObjectMeta: v1.ObjectMeta{
 					Name: "test-component",
@@ -208,6 +275,68 @@ func TestComponentCreateValidatingWebhook(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "component git source url must name a repository",
+			client: fakeClient,
+			err:    "spec.source.git.url: Invalid value: \"https://github.com/devfile-samples\": path \"/devfile-samples\" does not name a repository, expected /<organization>/<repository>",
+			newComp: appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "test-component",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "component1",
+					Application:   "application1",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://github.com/devfile-samples",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:   "valid component with ssh git src",
+			client: fakeClient,
+			newComp: appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "test-component",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "component1",
+					Application:   "application1",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "ssh://git@github.com/devfile-samples/devfile-sample-java-springboot-basic.git",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:   "component with invalid git scheme src",
+			client: fakeClient,
+			err:    "spec.source.git.url: Invalid value: \"ftp://github.com/devfile-samples/devfile-sample-java-springboot-basic\": unsupported scheme \"ftp\"",
+			newComp: appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "test-component",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "component1",
+					Application:   "application1",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "ftp://github.com/devfile-samples/devfile-sample-java-springboot-basic",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:   "valid component with container image",
 			client: fakeClient,
@@ -223,7 +352,58 @@ func TestComponentCreateValidatingWebhook(t *testing.T) {
 			},
 		},
 		{
-			name:   "validate succeeds but updating nudged component fails",
+			name:   "component cannot nudge itself",
+			client: fakeClient,
+			err:    "component test-component cannot nudge itself via build-nudges-ref",
+			newComp: appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "component1",
+					Application:    "application1",
+					ContainerImage: "image",
+					BuildNudgesRef: []string{"component1", "test-component"},
+				},
+			},
+		},
+		{
+			name:   "build-nudges-ref cannot contain duplicates",
+			client: fakeClient,
+			err:    "spec.build-nudges-ref[2]: Duplicate value: \"component1\"",
+			newComp: appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "component1",
+					Application:    "application1",
+					ContainerImage: "image",
+					BuildNudgesRef: []string{"component1", "component3", "component1"},
+				},
+			},
+		},
+		{
+			name:   "nudged component must belong to the same application",
+			client: fakeClient,
+			err:    "component test-component cannot nudge component component4 via build-nudges-ref: it belongs to application application2 instead of application1",
+			newComp: appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "component1",
+					Application:    "application1",
+					ContainerImage: "image",
+					BuildNudgesRef: []string{"component3", "component4"},
+				},
+			},
+		},
+		{
+			name:   "error listing the nudged components",
 			client: fakeErrorClient,
 			err:    "some error",
 			newComp: appstudiov1alpha1.Component{
@@ -233,30 +413,1259 @@ func TestComponentCreateValidatingWebhook(t *testing.T) {
 				},
 				Spec: appstudiov1alpha1.ComponentSpec{
 					ComponentName:  "component1",
-					Application:    "application",
-					ContainerImage: "image",
-					BuildNudgesRef: []string{
-						"alternating-error-comp",
-					},
+					Application:    "application",
+					ContainerImage: "image",
+					BuildNudgesRef: []string{
+						"alternating-error-comp",
+					},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client: test.client,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+			_, err := compWebhook.ValidateCreate(context.Background(), &test.newComp)
+
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
+
+func TestComponentCreateValidatingWebhookGitHosts(t *testing.T) {
+	allowedGitHosts := []string{"github.com", "gitlab.com", "git.example.com:8443"}
+
+	tests := []struct {
+		name         string
+		url          string
+		allowedHosts []string
+		err          string
+	}{
+		{
+			name: "git source on an allowed host",
+			url:  "https://github.com/devfile-samples/devfile-sample-python-basic",
+		},
+		{
+			name: "git source on an allowed self-hosted instance",
+			url:  "https://git.example.com:8443/team/repo",
+		},
+		{
+			name: "scp-like git source on an allowed host",
+			url:  "git@gitlab.com:team/repo.git",
+		},
+		{
+			name: "ssh git source on an allowed self-hosted instance",
+			url:  "ssh://git@git.example.com:8443/team/repo.git",
+		},
+		{
+			name: "git source on a host that isn't allowed",
+			url:  "https://bitbucket.org/team/repo",
+			err:  "git host bitbucket.org is not allowed, allowed hosts are: github.com, gitlab.com, git.example.com:8443",
+		},
+		{
+			name: "git source on an IP address",
+			url:  "http://10.0.0.1/team/repo",
+			err:  "git host 10.0.0.1 is not allowed",
+		},
+		{
+			name: "git source on an internal hostname",
+			url:  "http://gitea.svc.cluster.local/team/repo",
+			err:  "git host gitea.svc.cluster.local is not allowed",
+		},
+		{
+			name: "git source with a non-http scheme",
+			url:  "file://github.com/team/repo",
+			err:  "unsupported scheme \"file\"",
+		},
+		{
+			name:         "git source on any public host with the wildcard",
+			url:          "https://bitbucket.org/team/repo",
+			allowedHosts: []string{},
+		},
+		{
+			name:         "git source on a link-local IP address with the wildcard",
+			url:          "http://169.254.169.254/org/repo",
+			allowedHosts: []string{},
+			err:          "git host 169.254.169.254 is not allowed, IP addresses must be listed in ALLOWED_GIT_HOSTS",
+		},
+		{
+			name:         "git source on an IPv6 loopback address with the wildcard",
+			url:          "ssh://[::1]/a/b",
+			allowedHosts: []string{},
+			err:          "git host ::1 is not allowed, IP addresses must be listed in ALLOWED_GIT_HOSTS",
+		},
+		{
+			name:         "git source on a shorthand IP address with the wildcard",
+			url:          "http://0x7f.1/a/b",
+			allowedHosts: []string{},
+			err:          "git host 0x7f.1 is not allowed, IP addresses must be listed in ALLOWED_GIT_HOSTS",
+		},
+		{
+			name:         "git source on localhost with the wildcard",
+			url:          "http://localhost/a/b",
+			allowedHosts: []string{},
+			err:          "git host localhost is not allowed, internal hosts must be listed in ALLOWED_GIT_HOSTS",
+		},
+		{
+			name:         "git source on a single-label host with the wildcard",
+			url:          "https://gitea/team/repo",
+			allowedHosts: []string{},
+			err:          "git host gitea is not allowed, internal hosts must be listed in ALLOWED_GIT_HOSTS",
+		},
+		{
+			name:         "git source on a service host with the wildcard",
+			url:          "http://kubernetes.default.svc/a/b",
+			allowedHosts: []string{},
+			err:          "git host kubernetes.default.svc is not allowed, internal hosts must be listed in ALLOWED_GIT_HOSTS",
+		},
+		{
+			name:         "git source on a cluster-local host with the wildcard",
+			url:          "http://gitea.gitea.svc.cluster.local/team/repo",
+			allowedHosts: []string{},
+			err:          "git host gitea.gitea.svc.cluster.local is not allowed, internal hosts must be listed in ALLOWED_GIT_HOSTS",
+		},
+		{
+			name:         "git source on an explicitly allowed internal host",
+			url:          "http://gitea.gitea.svc.cluster.local/team/repo",
+			allowedHosts: []string{"gitea.gitea.svc.cluster.local"},
+		},
+		{
+			name:         "git source on an explicitly allowed IP address",
+			url:          "http://10.0.0.1/team/repo",
+			allowedHosts: []string{"10.0.0.1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client: NewFakeClient(t),
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+				allowedGitHosts: allowedGitHosts,
+			}
+			if test.allowedHosts != nil {
+				compWebhook.allowedGitHosts = test.allowedHosts
+			}
+
+			_, err := compWebhook.ValidateCreate(context.Background(), &appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "component1",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL:      test.url,
+								Revision: "main",
+							},
+						},
+					},
+				},
+			})
+
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
+
+func TestAllowedGitHostsFromEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{
+			name: "github.com and gitlab.com are allowed by default",
+			want: defaultAllowedGitHosts,
+		},
+		{
+			name:  "wildcard allows any public host",
+			value: "github.com, *",
+		},
+		{
+			name:  "hosts are trimmed and lowercased",
+			value: " GitHub.com ,gitlab.example.com:8443,",
+			want:  []string{"github.com", "gitlab.example.com:8443"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("ALLOWED_GIT_HOSTS", test.value)
+			assert.Equal(t, test.want, allowedGitHostsFromEnv())
+		})
+	}
+}
+
+func TestComponentCreateValidatingWebhookGitSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		gitSource appstudiov1alpha1.GitSource
+		err       string
+	}{
+		{
+			name: "valid git source",
+			gitSource: appstudiov1alpha1.GitSource{
+				Revision:      "release/v1.0",
+				Context:       "services/frontend/",
+				DevfileURL:    "devfiles/devfile.yaml",
+				DockerfileURL: "https://raw.githubusercontent.com/test/repo/main/Dockerfile",
+			},
+		},
+		{
+			name: "revision can be a full commit sha",
+			gitSource: appstudiov1alpha1.GitSource{
+				Revision: "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+			},
+		},
+		{
+			name: "revision must be a legal ref name",
+			gitSource: appstudiov1alpha1.GitSource{
+				Revision: "main..dev",
+			},
+			err: "spec.source.git.revision: Invalid value: \"main..dev\": \"main..dev\" is not a valid git ref name: it must not contain '..'",
+		},
+		{
+			name: "context cannot be absolute",
+			gitSource: appstudiov1alpha1.GitSource{
+				Context: "/services/frontend",
+			},
+			err: "spec.source.git.context: Invalid value: \"/services/frontend\": context \"/services/frontend\" must be a clean relative path inside the repository",
+		},
+		{
+			name: "context cannot leave the repository",
+			gitSource: appstudiov1alpha1.GitSource{
+				Context: "services/../../frontend",
+			},
+			err: "spec.source.git.context: Invalid value",
+		},
+		{
+			name: "devfile url cannot leave the context",
+			gitSource: appstudiov1alpha1.GitSource{
+				DevfileURL: "../devfile.yaml",
+			},
+			err: "spec.source.git.devfileUrl: Invalid value: \"../devfile.yaml\": \"../devfile.yaml\" must be an http(s) URL or a clean relative path inside the context",
+		},
+		{
+			name: "dockerfile url must be an http(s) url",
+			gitSource: appstudiov1alpha1.GitSource{
+				DockerfileURL: "file:///etc/Dockerfile",
+			},
+			err: "spec.source.git.dockerfileUrl: Invalid value: \"file:///etc/Dockerfile\": unsupported scheme \"file\"",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client: NewFakeClient(t),
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+
+			gitSource := test.gitSource
+			gitSource.URL = "https://github.com/test/repo"
+			_, err := compWebhook.ValidateCreate(context.Background(), &appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "component1",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &gitSource,
+						},
+					},
+				},
+			})
+
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
+
+func TestComponentCreateValidatingWebhookReportsAllErrors(t *testing.T) {
+	compWebhook := ComponentWebhook{
+		admissionConfig: admissionConfig{
+			client: NewFakeClient(t),
+			log: zap.New(zap.UseFlagOptions(&zap.Options{
+				Development: true,
+				TimeEncoder: zapcore.ISO8601TimeEncoder,
+			})),
+		},
+	}
+
+	replicas := -1
+	_, err := compWebhook.ValidateCreate(context.Background(), &appstudiov1alpha1.Component{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test-component",
+			Namespace: "default",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ComponentName: "component1",
+			Application:   "application1",
+			Source: appstudiov1alpha1.ComponentSource{
+				ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+					GitSource: &appstudiov1alpha1.GitSource{
+						URL:     "ftp://github.com/test/repo",
+						Context: "/services/frontend",
+					},
+				},
+			},
+			Replicas:       &replicas,
+			BuildNudgesRef: []string{"component1", "component2", "component1"},
+		},
+	})
+
+	require.Error(t, err)
+	assert.True(t, k8sErrors.IsInvalid(err), "expected an Invalid error, got %v", err)
+
+	statusErr := &k8sErrors.StatusError{}
+	require.ErrorAs(t, err, &statusErr)
+	var fields []string
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	assert.Equal(t, []string{"spec.source.git.url", "spec.source.git.context", "spec.replicas", "spec.build-nudges-ref[2]"}, fields)
+}
+
+func TestComponentCreateValidatingWebhookContainerImage(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	tests := []struct {
+		name                           string
+		image                          string
+		application                    string
+		allowedImageRegistries         []string
+		requireImageDigestInProduction bool
+		err                            string
+	}{
+		{
+			name:  "valid image reference",
+			image: "quay.io/test/image:v1.0.0",
+		},
+		{
+			name:  "malformed image reference",
+			image: "quay.io/Test/image",
+			err:   "spec.containerImage: Invalid value: \"quay.io/Test/image\": image reference \"quay.io/Test/image\" has an invalid repository",
+		},
+		{
+			name:                   "image from an allowed registry",
+			image:                  "quay.io/test/image:v1.0.0",
+			allowedImageRegistries: []string{"registry.redhat.io", "quay.io/test"},
+		},
+		{
+			name:                   "image from a registry that isn't allowed",
+			image:                  "docker.io/test/image:v1.0.0",
+			allowedImageRegistries: []string{"registry.redhat.io", "quay.io/test"},
+			err:                    "spec.containerImage: Invalid value: \"docker.io/test/image:v1.0.0\": image docker.io/test/image:v1.0.0 is not hosted in an allowed registry, allowed registries are: registry.redhat.io, quay.io/test",
+		},
+		{
+			name:                   "image from a repository that isn't allowed in an allowed registry",
+			image:                  "quay.io/other/image:v1.0.0",
+			allowedImageRegistries: []string{"quay.io/test"},
+			err:                    "is not hosted in an allowed registry",
+		},
+		{
+			name:                           "production application requires a digest",
+			image:                          "quay.io/test/image:v1.0.0",
+			application:                    "production-application",
+			requireImageDigestInProduction: true,
+			err:                            "spec.containerImage: Invalid value: \"quay.io/test/image:v1.0.0\": image quay.io/test/image:v1.0.0 must be referenced by digest",
+		},
+		{
+			name:                           "production application with a digest",
+			image:                          "quay.io/test/image@" + digest,
+			application:                    "production-application",
+			requireImageDigestInProduction: true,
+		},
+		{
+			name:                           "non production application doesn't require a digest",
+			image:                          "quay.io/test/image:v1.0.0",
+			application:                    "application1",
+			requireImageDigestInProduction: true,
+		},
+		{
+			name:        "digest isn't required unless enabled",
+			image:       "quay.io/test/image:v1.0.0",
+			application: "production-application",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fakeClient := NewFakeClient(t)
+			for _, app := range []appstudiov1alpha1.Application{
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "application1",
+						Namespace: "default",
+					},
+				},
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "production-application",
+						Namespace: "default",
+						Labels: map[string]string{
+							environmentLabel: productionEnvironment,
+						},
+					},
+				},
+			} {
+				err := fakeClient.Create(context.Background(), &app)
+				require.NoError(t, err)
+			}
+
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client: fakeClient,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+				allowedImageRegistries:         test.allowedImageRegistries,
+				requireImageDigestInProduction: test.requireImageDigestInProduction,
+			}
+
+			_, err := compWebhook.ValidateCreate(context.Background(), &appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "component1",
+					Application:    test.application,
+					ContainerImage: test.image,
+				},
+			})
+
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
+
+func TestComponentValidatingWebhookSecret(t *testing.T) {
+	newSecret := func(name string, secretType corev1.SecretType, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Type: secretType,
+			Data: data,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		oldComp  *appstudiov1alpha1.Component
+		warnings admission.Warnings
+		err      string
+	}{
+		{
+			name:   "basic-auth secret with a password",
+			secret: "basic-auth-secret",
+		},
+		{
+			name:   "ssh-auth secret with a private key",
+			secret: "ssh-auth-secret",
+		},
+		{
+			name:   "opaque secret with a token",
+			secret: "token-secret",
+		},
+		{
+			name:     "missing secret is a warning",
+			secret:   "missing-secret",
+			warnings: admission.Warnings{"secret missing-secret does not exist yet, component test-component will not be able to access its git repository until it is created"},
+		},
+		{
+			name:   "secret of an unsupported type",
+			secret: "tls-secret",
+			err:    "spec.secret: Invalid value: \"tls-secret\": secret tls-secret has type kubernetes.io/tls, supported types are: kubernetes.io/basic-auth, kubernetes.io/ssh-auth, Opaque",
+		},
+		{
+			name:   "basic-auth secret without a password",
+			secret: "empty-basic-auth-secret",
+			err:    "spec.secret: Invalid value: \"empty-basic-auth-secret\": secret empty-basic-auth-secret of type kubernetes.io/basic-auth must contain the password key",
+		},
+		{
+			name:   "opaque secret without a token",
+			secret: "empty-token-secret",
+			err:    "spec.secret: Invalid value: \"empty-token-secret\": secret empty-token-secret of type Opaque must contain the token key",
+		},
+		{
+			name:   "unchanged secret is not revalidated on update",
+			secret: "tls-secret",
+			oldComp: &appstudiov1alpha1.Component{
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "component1",
+					Secret:        "tls-secret",
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client: NewFakeClient(t),
+					// Secrets are read from the API server, as the manager doesn't cache them
+					apiReader: NewFakeClient(t,
+						newSecret("basic-auth-secret", corev1.SecretTypeBasicAuth, map[string][]byte{corev1.BasicAuthPasswordKey: []byte("token")}),
+						newSecret("ssh-auth-secret", corev1.SecretTypeSSHAuth, map[string][]byte{corev1.SSHAuthPrivateKey: []byte("key")}),
+						newSecret("token-secret", corev1.SecretTypeOpaque, map[string][]byte{"token": []byte("token")}),
+						newSecret("tls-secret", corev1.SecretTypeTLS, map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")}),
+						newSecret("empty-basic-auth-secret", corev1.SecretTypeBasicAuth, map[string][]byte{corev1.BasicAuthUsernameKey: []byte("user")}),
+						newSecret("empty-token-secret", corev1.SecretTypeOpaque, nil),
+					),
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+
+			comp := &appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "component1",
+					ContainerImage: "quay.io/test/image:latest",
+					Secret:         test.secret,
+				},
+			}
+
+			var warnings admission.Warnings
+			var err error
+			if test.oldComp != nil {
+				warnings, err = compWebhook.ValidateUpdate(context.Background(), test.oldComp, comp)
+			} else {
+				warnings, err = compWebhook.ValidateCreate(context.Background(), comp)
+			}
+
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Contains(t, err.Error(), test.err)
+			}
+			assert.Equal(t, test.warnings, warnings)
+		})
+	}
+}
+
+func TestComponentValidatingWebhookRuntimeFields(t *testing.T) {
+	replicas := func(r int) *int { return &r }
+
+	tests := []struct {
+		name    string
+		oldSpec *appstudiov1alpha1.ComponentSpec
+		spec    appstudiov1alpha1.ComponentSpec
+		errs    []string
+	}{
+		{
+			name: "valid runtime fields",
+			spec: appstudiov1alpha1.ComponentSpec{
+				Replicas:   replicas(2),
+				TargetPort: 8080,
+				Route:      "my-app.apps.example.com",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("256Mi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("1"),
+						corev1.ResourceMemory: resource.MustParse("256Mi"),
+					},
+				},
+				Env: []corev1.EnvVar{
+					{Name: "LOG_LEVEL", Value: "debug"},
+					{Name: "my.setting", Value: "true"},
+				},
+			},
+		},
+		{
+			name: "all violations are reported together",
+			spec: appstudiov1alpha1.ComponentSpec{
+				Replicas:   replicas(-1),
+				TargetPort: 70000,
+				Route:      "My_Route",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("2"),
+						corev1.ResourceMemory: resource.MustParse("-1Gi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+					},
+				},
+				Env: []corev1.EnvVar{
+					{Name: "LOG_LEVEL", Value: "debug"},
+					{Name: "1INVALID=NAME", Value: "true"},
+					{Name: "LOG_LEVEL", Value: "info"},
+				},
+			},
+			errs: []string{
+				"spec.replicas: Invalid value: -1: must be greater than or equal to 0",
+				"spec.targetPort: Invalid value: 70000: must be between 1 and 65535, inclusive",
+				"spec.resources.requests[cpu]: Invalid value: \"2\": must be less than or equal to cpu limit of 1",
+				"spec.resources.requests[memory]: Invalid value: \"-1Gi\": must be greater than or equal to 0",
+				"spec.env[1].name: Invalid value: \"1INVALID=NAME\"",
+				"spec.env[2].name: Duplicate value: \"LOG_LEVEL\"",
+				"spec.route: Invalid value: \"My_Route\"",
+			},
+		},
+		{
+			name: "unchanged invalid fields are not revalidated on update",
+			oldSpec: &appstudiov1alpha1.ComponentSpec{
+				Replicas: replicas(-1),
+			},
+			spec: appstudiov1alpha1.ComponentSpec{
+				Replicas:   replicas(-1),
+				TargetPort: 8080,
+			},
+		},
+		{
+			name: "changed fields are validated on update",
+			oldSpec: &appstudiov1alpha1.ComponentSpec{
+				Replicas: replicas(1),
+			},
+			spec: appstudiov1alpha1.ComponentSpec{
+				Replicas: replicas(-1),
+			},
+			errs: []string{
+				"spec.replicas: Invalid value: -1: must be greater than or equal to 0",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client: NewFakeClient(t),
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+
+			spec := test.spec
+			spec.ComponentName = "component1"
+			spec.ContainerImage = "quay.io/test/image:latest"
+			newComp := &appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: spec,
+			}
+
+			var err error
+			if test.oldSpec == nil {
+				_, err = compWebhook.ValidateCreate(context.Background(), newComp)
+			} else {
+				oldComp := newComp.DeepCopy()
+				oldComp.Spec.Replicas = test.oldSpec.Replicas
+				oldComp.Spec.TargetPort = test.oldSpec.TargetPort
+				_, err = compWebhook.ValidateUpdate(context.Background(), oldComp, newComp)
+			}
+
+			if len(test.errs) == 0 {
+				assert.Nil(t, err)
+			} else {
+				require.Error(t, err)
+				for _, msg := range test.errs {
+					assert.Contains(t, err.Error(), msg)
+				}
+			}
+		})
+	}
+}
+
+func TestComponentCreateValidatingWebhookWarnings(t *testing.T) {
+	fakeClient := setUpComponents(t)
+
+	app := appstudiov1alpha1.Application{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "application1",
+			Namespace: "default",
+		},
+		Spec: appstudiov1alpha1.ApplicationSpec{
+			DisplayName: "app",
+		},
+	}
+	err := fakeClient.Create(context.Background(), &app)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		newComp  appstudiov1alpha1.Component
+		warnings admission.Warnings
+	}{
+		{
+			name: "no warnings",
+			newComp: appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "component1",
+					Application:    "application1",
+					BuildNudgesRef: []string{"component3"},
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL:      "https://github.com/test/repo",
+								Revision: "main",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "git source without revision",
+			newComp: appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "component1",
+					Application:   "application1",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://github.com/test/repo",
+							},
+						},
+					},
+				},
+			},
+			warnings: admission.Warnings{"git source https://github.com/test/repo does not specify a revision, the default branch of the repository will be used"},
+		},
+		{
+			name: "missing application and nudged component",
+			newComp: appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "component1",
+					Application:    "application-not-found",
+					ContainerImage: "image",
+					BuildNudgesRef: []string{"component-not-found"},
+				},
+			},
+			warnings: admission.Warnings{
+				"application application-not-found does not exist yet, component test-component will be added to it once it is created",
+				"component component-not-found nudged via build-nudges-ref does not exist yet",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client: fakeClient,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+			warnings, err := compWebhook.ValidateCreate(context.Background(), &test.newComp)
+			require.NoError(t, err)
+			assert.Equal(t, test.warnings, warnings)
+		})
+	}
+}
+
+func TestComponentPolicies(t *testing.T) {
+	fakeClient := NewFakeClient(t,
+		&corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: policy.ConfigMapName, Namespace: "application-service"},
+			Data: map[string]string{policy.ConfigMapKey: `
+- name: pin-revision-in-prod
+  kinds: [Component]
+  expression: "!has(namespaceObject.metadata.labels) || namespaceObject.metadata.labels.tier != 'prod' || has(object.spec.source.git.revision)"
+  message: components in prod namespaces must pin a revision
+- name: keep-revision-pinned
+  kinds: [Component]
+  operations: [UPDATE]
+  expression: "!has(oldObject.spec.source.git.revision) || has(object.spec.source.git.revision)"
+  message: the revision cannot be unpinned
+`},
+		},
+		&corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "prod", Labels: map[string]string{"tier": "prod"}}},
+		&corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "dev"}},
+	)
+
+	newComponent := func(namespace string, revision string) *appstudiov1alpha1.Component {
+		return &appstudiov1alpha1.Component{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "test-component",
+				Namespace: namespace,
+			},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ComponentName: "component1",
+				Source: appstudiov1alpha1.ComponentSource{
+					ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+						GitSource: &appstudiov1alpha1.GitSource{URL: "https://github.com/test/repo", Revision: revision},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		newComp *appstudiov1alpha1.Component
+		oldComp *appstudiov1alpha1.Component
+		err     string
+	}{
+		{
+			name:    "pinned revision in a prod namespace",
+			newComp: newComponent("prod", "main"),
+		},
+		{
+			name:    "unpinned revision in a dev namespace",
+			newComp: newComponent("dev", ""),
+		},
+		{
+			name:    "unpinned revision in a prod namespace",
+			newComp: newComponent("prod", ""),
+			err:     "component test-component was denied: denied by policy pin-revision-in-prod: components in prod namespaces must pin a revision",
+		},
+		{
+			name:    "every denying policy is reported",
+			newComp: newComponent("prod", ""),
+			oldComp: newComponent("prod", "main"),
+			err:     "component test-component was denied: denied by policy pin-revision-in-prod: components in prod namespaces must pin a revision; denied by policy keep-revision-pinned: the revision cannot be unpinned",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client:   fakeClient,
+					policies: policy.NewLoader(fakeClient, "application-service", policy.ConfigMapName),
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+
+			var err error
+			if test.oldComp != nil {
+				_, err = compWebhook.ValidateUpdate(context.Background(), test.oldComp, test.newComp)
+			} else {
+				_, err = compWebhook.ValidateCreate(context.Background(), test.newComp)
+			}
+
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestComponentPoliciesLoadError(t *testing.T) {
+	tests := []struct {
+		name   string
+		client client.Reader
+	}{
+		{
+			name: "invalid policies",
+			client: NewFakeClient(t, &corev1.ConfigMap{
+				ObjectMeta: v1.ObjectMeta{Name: policy.ConfigMapName, Namespace: "application-service"},
+				Data:       map[string]string{policy.ConfigMapKey: "- name: deny-all\n  expression: \"1\""},
+			}),
+		},
+		{
+			name: "ConfigMap cannot be read",
+			client: func() client.Reader {
+				fakeErrorClient := NewFakeErrorClient(t)
+				fakeErrorClient.MockGet = func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+					return fmt.Errorf("some error")
+				}
+				return fakeErrorClient
+			}(),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client:   NewFakeClient(t),
+					policies: policy.NewLoader(test.client, "application-service", policy.ConfigMapName),
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+
+			// The request is admitted without the policies, and the load error is counted
+			loadErrors := testutil.ToFloat64(policyLoadErrors.WithLabelValues("Component"))
+			_, err := compWebhook.ValidateCreate(context.Background(), &appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "component1",
+					ContainerImage: "quay.io/test/image:latest",
+				},
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, loadErrors+1, testutil.ToFloat64(policyLoadErrors.WithLabelValues("Component")))
+		})
+	}
+}
+
+func TestComponentEnforcementModes(t *testing.T) {
+	fakeClient := NewFakeClient(t,
+		&corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: policy.ConfigMapName, Namespace: "application-service"},
+			Data: map[string]string{
+				policy.ConfigMapKey: `
+- name: require-secret
+  kinds: [Component]
+  expression: "has(object.spec.secret)"
+  message: components must use a secret
+  mode: warn
+`,
+				policy.ModesConfigMapKey: "spec.route: audit",
+			},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		modes    policy.Modes
+		dryRun   bool
+		warnings admission.Warnings
+		events   []string
+		err      string
+	}{
+		{
+			name: "violations are enforced by default",
+			err:  "spec.containerImage: Invalid value: \"quay.io/Test/image\"",
+		},
+		{
+			name:     "violations in warn mode are returned as warnings",
+			modes:    policy.Modes{"spec.containerImage": policy.ModeWarn},
+			warnings: admission.Warnings{"spec.containerImage: Invalid value: \"quay.io/Test/image\": image reference \"quay.io/Test/image\" has an invalid repository \"Test/image\": it must consist of lower case alphanumeric components separated by '/', optionally with '.', '_' or '-' separators", "denied by policy require-secret: components must use a secret"},
+			events: []string{
+				"Warning PolicyWarning spec.containerImage: Invalid value: \"quay.io/Test/image\": image reference \"quay.io/Test/image\" has an invalid repository \"Test/image\": it must consist of lower case alphanumeric components separated by '/', optionally with '.', '_' or '-' separators",
+				"Warning PolicyWarning denied by policy require-secret: components must use a secret",
+			},
+		},
+		{
+			name:     "violations in warn mode of dry-run requests are not recorded as events",
+			modes:    policy.Modes{"spec.containerImage": policy.ModeAudit},
+			dryRun:   true,
+			warnings: admission.Warnings{"denied by policy require-secret: components must use a secret"},
+		},
+		{
+			name:     "violations in audit mode are only logged",
+			modes:    policy.Modes{"spec.containerImage": policy.ModeAudit},
+			warnings: admission.Warnings{"denied by policy require-secret: components must use a secret"},
+			events:   []string{"Warning PolicyWarning denied by policy require-secret: components must use a secret"},
+		},
+		{
+			name:     "violations in warn mode of denied requests are not recorded as events",
+			modes:    policy.Modes{"spec.containerImage": policy.ModeWarn, "require-secret": policy.ModeEnforce},
+			warnings: admission.Warnings{"spec.containerImage: Invalid value: \"quay.io/Test/image\": image reference \"quay.io/Test/image\" has an invalid repository \"Test/image\": it must consist of lower case alphanumeric components separated by '/', optionally with '.', '_' or '-' separators"},
+			err:      "component test-component was denied: denied by policy require-secret: components must use a secret",
+		},
+		{
+			name:  "modes set at startup are overridden by the ConfigMap",
+			modes: policy.Modes{"spec.containerImage": policy.ModeAudit, "spec.route": policy.ModeEnforce, "require-secret": policy.ModeEnforce},
+			err:   "component test-component was denied: denied by policy require-secret: components must use a secret",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client:           fakeClient,
+					recorder:         recorder,
+					policies:         policy.NewLoader(fakeClient, "application-service", policy.ConfigMapName),
+					enforcementModes: test.modes,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+
+			ctx := context.Background()
+			if test.dryRun {
+				ctx = admission.NewContextWithRequest(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{DryRun: &test.dryRun}})
+			}
+			audited := testutil.ToFloat64(unenforcedViolations.WithLabelValues("Component", "spec.route", "audit"))
+			warnings, err := compWebhook.ValidateCreate(ctx, &appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "component1",
+					ContainerImage: "quay.io/Test/image",
+					Route:          "Invalid_Route",
 				},
+			})
+
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Contains(t, err.Error(), test.err)
+			}
+			assert.Equal(t, test.warnings, warnings)
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			assert.Equal(t, test.events, events)
+			// The invalid route is always audited, as its mode is set in the ConfigMap
+			assert.Equal(t, audited+1, testutil.ToFloat64(unenforcedViolations.WithLabelValues("Component", "spec.route", "audit")))
+		})
+	}
+}
+
+func TestComponentAdmissionMetrics(t *testing.T) {
+	newComponent := func(image string, nudges ...string) *appstudiov1alpha1.Component {
+		return &appstudiov1alpha1.Component{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "test-component",
+				Namespace: "default",
 			},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ComponentName:  "component1",
+				ContainerImage: image,
+				BuildNudgesRef: nudges,
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		client     client.Client
+		quotas     quotas
+		component  *appstudiov1alpha1.Component
+		outcome    string
+		reason     string
+		apiOutcome string
+	}{
+		{
+			name:       "allowed request",
+			client:     setUpComponents(t),
+			component:  newComponent("quay.io/test/image:latest", "component-not-found"),
+			outcome:    "allowed",
+			reason:     "",
+			apiOutcome: "success",
+		},
+		{
+			name:      "request with an invalid field",
+			client:    setUpComponents(t),
+			component: newComponent("quay.io/Test/image"),
+			outcome:   "denied",
+			reason:    "invalid",
+		},
+		{
+			name:       "request exceeding the quota",
+			client:     setUpComponents(t),
+			quotas:     quotas{maxComponents: 1},
+			component:  newComponent("quay.io/test/image:latest"),
+			outcome:    "denied",
+			reason:     "quota",
+			apiOutcome: "success",
+		},
+		{
+			name:       "request that cannot be validated",
+			client:     NewFakeErrorClient(t),
+			component:  newComponent("quay.io/test/image:latest", "component1"),
+			outcome:    "error",
+			reason:     "internal",
+			apiOutcome: "error",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client: newInstrumentedClient(test.client, componentWebhookName),
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+				quotas: test.quotas,
+			}
+
+			requests := admissionRequests.WithLabelValues("component", "create", test.outcome, test.reason)
+			before := testutil.ToFloat64(requests)
+			listsBefore := histogramSampleCount(t, apiRequestDuration.WithLabelValues("component", "list", "ComponentList", test.apiOutcome))
+
+			_, _ = compWebhook.ValidateCreate(context.Background(), test.component)
+
+			assert.Equal(t, before+1, testutil.ToFloat64(requests))
+			if test.apiOutcome != "" {
+				assert.Less(t, listsBefore, histogramSampleCount(t, apiRequestDuration.WithLabelValues("component", "list", "ComponentList", test.apiOutcome)))
+			}
+		})
+	}
+}
+
+// histogramSampleCount returns the number of observations of the given histogram
+func histogramSampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	var metric dto.Metric
+	require.NoError(t, observer.(prometheus.Histogram).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestComponentQuota(t *testing.T) {
+	tests := []struct {
+		name        string
+		quotas      quotas
+		annotations map[string]string
+		application string
+		err         string
+	}{
+		{
+			name:        "no quota is enforced by default",
+			application: "application1",
+		},
+		{
+			name:        "component below the configured quotas can be created",
+			quotas:      quotas{maxComponents: 4, maxComponentsPerApplication: 3},
+			application: "application1",
+		},
+		{
+			name:        "component above the namespace quota cannot be created",
+			quotas:      quotas{maxComponents: 3},
+			application: "application2",
+			err:         "namespace default already contains 3 component(s), the limit is 3",
+		},
+		{
+			name:        "component above the application quota cannot be created",
+			quotas:      quotas{maxComponentsPerApplication: 2},
+			application: "application1",
+			err:         "application application1 already contains 2 component(s), the limit is 2",
+		},
+		{
+			name:        "application quota only counts the components of the application",
+			quotas:      quotas{maxComponentsPerApplication: 2},
+			application: "application2",
+		},
+		{
+			name:        "namespace annotation overrides the configured quota",
+			quotas:      quotas{maxComponentsPerApplication: 5},
+			annotations: map[string]string{maxComponentsPerApplicationAnnotation: "1"},
+			application: "application2",
+			err:         "application application2 already contains 1 component(s), the limit is 1",
+		},
+		{
+			name:        "invalid namespace annotation is ignored",
+			quotas:      quotas{maxComponents: 3},
+			annotations: map[string]string{maxComponentsAnnotation: "many"},
+			application: "application1",
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			fakeClient := NewFakeClient(t)
+			component3 := newNudgingComponent("component3", nil)
+			component3.Spec.Application = "application2"
+			for _, comp := range []appstudiov1alpha1.Component{newNudgingComponent("component1", nil), newNudgingComponent("component2", nil), component3} {
+				err := fakeClient.Create(context.Background(), &comp)
+				require.NoError(t, err)
+			}
+			// The namespace is read from the API server, as the manager doesn't cache namespaces
+			apiReader := NewFakeClient(t, &corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{
+					Name:        "default",
+					Annotations: test.annotations,
+				},
+			})
+
 			compWebhook := ComponentWebhook{
-				client: test.client,
-				log: zap.New(zap.UseFlagOptions(&zap.Options{
-					Development: true,
-					TimeEncoder: zapcore.ISO8601TimeEncoder,
-				})),
+				admissionConfig: admissionConfig{
+					client:    fakeClient,
+					apiReader: apiReader,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+				quotas: test.quotas,
 			}
-			err := compWebhook.ValidateCreate(context.Background(), &test.newComp)
 
+			warnings, err := compWebhook.ValidateCreate(context.Background(), &appstudiov1alpha1.Component{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "component4",
+					Namespace: "default",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "component4",
+					Application:    test.application,
+					ContainerImage: "quay.io/test/image:latest",
+				},
+			})
+
+			// The warnings are returned whether or not the quota denies the request
+			assert.Equal(t, admission.Warnings{fmt.Sprintf("application %s does not exist yet, component component4 will be added to it once it is created", test.application)}, warnings)
 			if test.err == "" {
 				assert.Nil(t, err)
 			} else {
-				assert.Contains(t, err.Error(), test.err)
+				assert.EqualError(t, err, test.err)
 			}
 		})
 	}
@@ -333,6 +1742,25 @@ func TestComponentUpdateValidatingWebhook(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "git source context cannot be changed to an absolute path",
+			client: fakeClient,
+			err:    "spec.source.git.context: Invalid value",
+			updateComp: appstudiov1alpha1.Component{
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "component",
+					Application:   "application",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL:     "http://link",
+								Context: "/context",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:   "non-url git source can be changed",
 			client: fakeClient,
@@ -364,7 +1792,7 @@ func TestComponentUpdateValidatingWebhook(t *testing.T) {
 			},
 		},
 		{
-			name:   "validate succeeds but updating nudged component fails",
+			name:   "error listing the nudged components",
 			client: fakeErrorClient,
 			err:    "some error",
 			updateComp: appstudiov1alpha1.Component{
@@ -407,13 +1835,15 @@ func TestComponentUpdateValidatingWebhook(t *testing.T) {
 			}
 			var err error
 			compWebhook := ComponentWebhook{
-				client: test.client,
-				log: zap.New(zap.UseFlagOptions(&zap.Options{
-					Development: true,
-					TimeEncoder: zapcore.ISO8601TimeEncoder,
-				})),
+				admissionConfig: admissionConfig{
+					client: test.client,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
 			}
-			err = compWebhook.ValidateUpdate(context.Background(), &originalComponent, &test.updateComp)
+			_, err = compWebhook.ValidateUpdate(context.Background(), &originalComponent, &test.updateComp)
 
 			if test.err == "" {
 				assert.Nil(t, err)
@@ -424,118 +1854,118 @@ func TestComponentUpdateValidatingWebhook(t *testing.T) {
 	}
 }
 
-func TestComponentDeleteValidatingWebhook(t *testing.T) {
-	fakeErrorClient := NewFakeErrorClient(t)
+func TestComponentUpdateValidatingWebhookEquivalentGitURL(t *testing.T) {
+	compWebhook := ComponentWebhook{
+		admissionConfig: admissionConfig{
+			log: zap.New(zap.UseFlagOptions(&zap.Options{
+				Development: true,
+				TimeEncoder: zapcore.ISO8601TimeEncoder,
+			})),
+		},
+	}
+	newComponentWithGitURL := func(url string) *appstudiov1alpha1.Component {
+		return &appstudiov1alpha1.Component{
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ComponentName: "component",
+				Application:   "application",
+				Source: appstudiov1alpha1.ComponentSource{
+					ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+						GitSource: &appstudiov1alpha1.GitSource{
+							URL: url,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	_, err := compWebhook.ValidateUpdate(context.Background(), newComponentWithGitURL("https://www.github.com/test/repo.git/"), newComponentWithGitURL("https://github.com/test/repo"))
+	assert.Nil(t, err)
+
+	_, err = compWebhook.ValidateUpdate(context.Background(), newComponentWithGitURL("https://github.com/test/repo"), newComponentWithGitURL("git@github.com:test/repo.git"))
+	assert.Nil(t, err)
+
+	_, err = compWebhook.ValidateUpdate(context.Background(), newComponentWithGitURL("https://github.com/test/repo"), newComponentWithGitURL("https://github.com/test/other-repo"))
+	assert.Error(t, err)
+}
+
+func TestComponentUpdateValidatingWebhookUnchangedBuildNudgesRef(t *testing.T) {
+	// The nudge of a Component of another application was accepted before the cross-application check was introduced
+	oldComp := newNudgingComponent("nudging", []string{"nudged"})
+	compWebhook := ComponentWebhook{
+		admissionConfig: admissionConfig{
+			client: NewFakeClient(t, &oldComp, newComponent("nudged", "application2")),
+			log: zap.New(zap.UseFlagOptions(&zap.Options{
+				Development: true,
+				TimeEncoder: zapcore.ISO8601TimeEncoder,
+			})),
+		},
+	}
+
+	// Setting the owner reference to the Application doesn't revalidate the nudges
+	newComp := oldComp.DeepCopy()
+	newComp.OwnerReferences = []v1.OwnerReference{{APIVersion: "appstudio.redhat.com/v1alpha1", Kind: "Application", Name: "application1", UID: "uid"}}
+	_, err := compWebhook.ValidateUpdate(context.Background(), &oldComp, newComp)
+	assert.Nil(t, err)
+
+	// Changing the nudges does
+	newComp.Spec.BuildNudgesRef = append(newComp.Spec.BuildNudgesRef, "other")
+	_, err = compWebhook.ValidateUpdate(context.Background(), &oldComp, newComp)
+	assert.ErrorContains(t, err, "spec.build-nudges-ref[0]: Invalid value: \"nudged\": component nudging cannot nudge component nudged via build-nudges-ref: it belongs to application application2 instead of application1")
+}
 
+func TestComponentDeleteValidatingWebhook(t *testing.T) {
 	tests := []struct {
-		name                 string
-		client               client.Client
-		newComp              appstudiov1alpha1.Component
-		componentName        string
-		nudgingComponentName string
+		name          string
+		client        client.Client
+		componentName string
 	}{
 		{
 			name:          "nudging component deleted",
-			newComp:       appstudiov1alpha1.Component{},
 			client:        setUpComponents(t),
 			componentName: "component1",
 		},
 		{
-			name:                 "nudged and nudging component deleted",
-			newComp:              appstudiov1alpha1.Component{},
-			client:               setUpComponents(t),
-			componentName:        "component2",
-			nudgingComponentName: "component1",
-		},
-		{
-			name:                 "nudged component deleted",
-			newComp:              appstudiov1alpha1.Component{},
-			client:               setUpComponents(t),
-			componentName:        "component3",
-			nudgingComponentName: "component2",
+			name:          "nudged and nudging component deleted",
+			client:        setUpComponents(t),
+			componentName: "component2",
 		},
 		{
-			name:          "error retrieving component",
-			newComp:       appstudiov1alpha1.Component{},
-			client:        fakeErrorClient,
-			componentName: "component2",
+			name:          "nudged component deleted",
+			client:        setUpComponents(t),
+			componentName: "component3",
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			compWebhook := ComponentWebhook{
-				client: test.client,
-				log: zap.New(zap.UseFlagOptions(&zap.Options{
-					Development: true,
-					TimeEncoder: zapcore.ISO8601TimeEncoder,
-				})),
-			}
-			var err error
-			component := &appstudiov1alpha1.Component{}
-
-			if test.name != "error retrieving component" {
-				if test.nudgingComponentName != "" {
-					// Validate parent component first in this test case
-					nudgingComponent := &appstudiov1alpha1.Component{}
-					err = test.client.Get(ctx, types.NamespacedName{Namespace: "default", Name: test.nudgingComponentName}, nudgingComponent)
-					require.NoError(t, err)
-					err = compWebhook.ValidateCreate(context.Background(), nudgingComponent)
-					require.NoError(t, err)
-				}
-				err = test.client.Get(ctx, types.NamespacedName{Namespace: "default", Name: test.componentName}, component)
-				require.NoError(t, err)
-
-				// First, run the create webhook to ensure statuses are properly set
-				err = compWebhook.ValidateCreate(context.Background(), component)
-				require.NoError(t, err)
-			} else {
-				component = &appstudiov1alpha1.Component{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "component2",
-						Namespace: "default",
-					},
-					TypeMeta: v1.TypeMeta{
-						APIVersion: "appstudio.redhat.com/v1alpha1",
-						Kind:       "Component",
-					},
-					Spec: appstudiov1alpha1.ComponentSpec{
-						ComponentName:  "component2",
-						Application:    "application",
-						BuildNudgesRef: []string{"component3"},
-					},
-					Status: appstudiov1alpha1.ComponentStatus{
-						BuildNudgedBy: []string{"component1"},
-					},
-				}
+				admissionConfig: admissionConfig{
+					client: test.client,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
 			}
 
-			// Next, run the delete webhook to ensure the fields previously set get removed
-			err = compWebhook.ValidateDelete(context.Background(), component)
+			var componentList appstudiov1alpha1.ComponentList
+			err := test.client.List(context.Background(), &componentList)
 			require.NoError(t, err)
 
-			if test.name != "error retrieving component" {
-				for _, v := range component.Spec.BuildNudgesRef {
-					nudgedComponent := &appstudiov1alpha1.Component{}
-					test.client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: v}, nudgedComponent)
-					require.NoError(t, err)
-
-					// The nudging component should no longer be present in the nudged component's status
-					if util.StrInList(test.componentName, nudgedComponent.Status.BuildNudgedBy) {
-						t.Errorf("TestComponentDeleteValidatingWebhook(): unexpected error, expected component %v to be removed from component %v's nudged-by list %v", test.componentName, v, nudgedComponent.Status.BuildNudgedBy)
-					}
-				}
+			component := &appstudiov1alpha1.Component{}
+			err = test.client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: test.componentName}, component)
+			require.NoError(t, err)
 
-				for _, v := range component.Status.BuildNudgedBy {
-					nudgingComponent := &appstudiov1alpha1.Component{}
-					test.client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: v}, nudgingComponent)
-					require.NoError(t, err)
+			_, err = compWebhook.ValidateDelete(context.Background(), component)
+			require.NoError(t, err)
 
-					if util.StrInList(test.componentName, nudgingComponent.Spec.BuildNudgesRef) {
-						t.Errorf("TestComponentDeleteValidatingWebhook(): unexpected error, expected component %v to be removed from component %v's build-nudges-ref list %v", test.componentName, v, nudgingComponent.Spec.BuildNudgesRef)
-					}
-				}
+			// The delete webhook must not have side effects, the nudge graph is cleaned up by the BuildNudgesReconciler
+			for _, comp := range componentList.Items {
+				currentComp := &appstudiov1alpha1.Component{}
+				err = test.client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: comp.Name}, currentComp)
+				require.NoError(t, err)
+				assert.Equal(t, comp.ResourceVersion, currentComp.ResourceVersion, "component %s was unexpectedly modified", comp.Name)
 			}
-
 		})
 	}
 }
@@ -545,19 +1975,34 @@ func TestValidateBuildNudgesRefGraph(t *testing.T) {
 	fakeErrorClient := setUpComponentsForFakeErrorClient(t)
 
 	compWebhook := ComponentWebhook{
-		client: fakeClient,
-		log: zap.New(zap.UseFlagOptions(&zap.Options{
-			Development: true,
-			TimeEncoder: zapcore.ISO8601TimeEncoder,
-		})),
+		admissionConfig: admissionConfig{
+			client: fakeClient,
+			log: zap.New(zap.UseFlagOptions(&zap.Options{
+				Development: true,
+				TimeEncoder: zapcore.ISO8601TimeEncoder,
+			})),
+		},
 	}
 
 	errCompWebhook := ComponentWebhook{
-		client: fakeErrorClient,
-		log: zap.New(zap.UseFlagOptions(&zap.Options{
-			Development: true,
-			TimeEncoder: zapcore.ISO8601TimeEncoder,
-		})),
+		admissionConfig: admissionConfig{
+			client: fakeErrorClient,
+			log: zap.New(zap.UseFlagOptions(&zap.Options{
+				Development: true,
+				TimeEncoder: zapcore.ISO8601TimeEncoder,
+			})),
+		},
+	}
+
+	warnCompWebhook := ComponentWebhook{
+		admissionConfig: admissionConfig{
+			client: fakeClient,
+			log: zap.New(zap.UseFlagOptions(&zap.Options{
+				Development: true,
+				TimeEncoder: zapcore.ISO8601TimeEncoder,
+			})),
+		},
+		warnOnCrossApplicationNudges: true,
 	}
 
 	tests := []struct {
@@ -565,6 +2010,7 @@ func TestValidateBuildNudgesRefGraph(t *testing.T) {
 		compName string
 		webhook  ComponentWebhook
 		errStr   string
+		warnings admission.Warnings
 	}{
 		{
 			name:     "simple component relationship, no errors",
@@ -575,21 +2021,34 @@ func TestValidateBuildNudgesRefGraph(t *testing.T) {
 			name:     "component references itself",
 			compName: "component-self-ref",
 			webhook:  compWebhook,
-			errStr:   "cycle detected: component component-self-ref cannot reference itself, directly or indirectly, via build-nudges-ref",
+			errStr:   "cycle detected in build-nudges-ref: component-self-ref -> component-self-ref",
 		},
 		{
 			name:     "nudged component belongs to different app",
 			compName: "component-invalid-app",
 			webhook:  compWebhook,
+			errStr:   "component component-invalid-app cannot nudge component component4 via build-nudges-ref: it belongs to application application2 instead of application1",
+		},
+		{
+			name:     "nudged component belongs to different app, only warn",
+			compName: "component-invalid-app",
+			webhook:  warnCompWebhook,
+			warnings: admission.Warnings{"component component-invalid-app cannot nudge component component4 via build-nudges-ref: it belongs to application application2 instead of application1"},
+		},
+		{
+			name:     "nudged component does not exist yet",
+			compName: "nudged-component-missing",
+			webhook:  compWebhook,
+			warnings: admission.Warnings{"component fake-fake nudged via build-nudges-ref does not exist yet"},
 		},
 		{
 			name:     "complex component relationship - some valid, some not valid (self referential)",
 			compName: "complexComponent",
 			webhook:  compWebhook,
-			errStr:   "cycle detected: component complexComponent cannot reference itself, directly or indirectly, via build-nudges-ref",
+			errStr:   "cycle detected in build-nudges-ref: complexComponent -> complexComponentNudged -> component7 -> component9 -> complexComponent",
 		},
 		{
-			name:     "unrelated get error from kubernetes",
+			name:     "unrelated list error from kubernetes",
 			compName: "component1",
 			webhook:  errCompWebhook,
 			errStr:   "some error",
@@ -600,140 +2059,185 @@ func TestValidateBuildNudgesRefGraph(t *testing.T) {
 			component := &appstudiov1alpha1.Component{}
 			fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: test.compName}, component)
 
-			err := test.webhook.validateBuildNudgesRefGraph(context.Background(), component.Spec.BuildNudgesRef, "default", test.compName)
+			warnings, errs, err := test.webhook.validateBuildNudgesRefGraph(context.Background(), component.Spec.BuildNudgesRef, "default", test.compName, component.Spec.Application, field.NewPath("spec", "build-nudges-ref"))
 			var errStr string
 			if err != nil {
 				errStr = err.Error()
+			} else if len(errs) != 0 {
+				errStr = errs[0].Detail
 			}
 			if errStr != test.errStr {
 				t.Errorf("TestValidateBuildNudgesRefGraph() unexpected error value: want %v, got %v", test.errStr, errStr)
 			}
+			assert.Equal(t, test.warnings, warnings)
 		})
 	}
 }
 
-func TestUpdateNudgedComponentStatus(t *testing.T) {
-	fakeClient := setUpComponents(t)
-	fakeErrorClient := setUpComponentsForFakeErrorClient(t)
-
-	compWebhook := ComponentWebhook{
-		client: fakeClient,
-		log: zap.New(zap.UseFlagOptions(&zap.Options{
-			Development: true,
-			TimeEncoder: zapcore.ISO8601TimeEncoder,
-		})),
-	}
-
-	errCompWebhook := ComponentWebhook{
-		client: fakeErrorClient,
-		log: zap.New(zap.UseFlagOptions(&zap.Options{
-			Development: true,
-			TimeEncoder: zapcore.ISO8601TimeEncoder,
-		})),
-	}
-
+func TestValidateBuildNudgesRefGraphLarge(t *testing.T) {
 	tests := []struct {
-		name     string
-		compName string
-		webhook  ComponentWebhook
-		errStr   string
+		name         string
+		components   func() []appstudiov1alpha1.Component
+		compName     string
+		nudges       []string
+		errStr       string
+		errStrPrefix string
+		errStrSuffix string
 	}{
 		{
-			name:     "simple component relationship",
-			compName: "component1",
-			webhook:  compWebhook,
+			name: "wide diamond-shaped graph without cycle",
+			components: func() []appstudiov1alpha1.Component {
+				// 20 layers of 50 components, where every component nudges every component of the next layer.
+				// Walking every path of this graph would never terminate.
+				var components []appstudiov1alpha1.Component
+				for layer := 0; layer < 20; layer++ {
+					for i := 0; i < 50; i++ {
+						var nudges []string
+						if layer < 19 {
+							for j := 0; j < 50; j++ {
+								nudges = append(nudges, fmt.Sprintf("comp-%d-%d", layer+1, j))
+							}
+						}
+						components = append(components, newNudgingComponent(fmt.Sprintf("comp-%d-%d", layer, i), nudges))
+					}
+				}
+				return components
+			},
+			compName: "root",
+			nudges:   []string{"comp-0-0", "comp-0-1", "comp-0-2"},
 		},
 		{
-			name:     "multiple nudged components",
-			compName: "component10",
-			webhook:  compWebhook,
+			name: "wide diamond-shaped graph with cycle back to the admitted component",
+			components: func() []appstudiov1alpha1.Component {
+				var components []appstudiov1alpha1.Component
+				for layer := 0; layer < 20; layer++ {
+					for i := 0; i < 50; i++ {
+						var nudges []string
+						if layer < 19 {
+							for j := 0; j < 50; j++ {
+								nudges = append(nudges, fmt.Sprintf("comp-%d-%d", layer+1, j))
+							}
+						} else if i == 49 {
+							nudges = []string{"root"}
+						}
+						components = append(components, newNudgingComponent(fmt.Sprintf("comp-%d-%d", layer, i), nudges))
+					}
+				}
+				return components
+			},
+			compName:     "root",
+			nudges:       []string{"comp-0-0"},
+			errStrPrefix: "cycle detected in build-nudges-ref: root -> comp-0-0 -> comp-1-0 -> comp-2-0",
+			errStrSuffix: "comp-18-0 -> comp-19-49 -> root",
 		},
 		{
-			name:     "simple component relationship, errors retrieving resource",
-			compName: "component1",
-			webhook:  errCompWebhook,
-			errStr:   "some error",
+			name: "long chain with cycle",
+			components: func() []appstudiov1alpha1.Component {
+				var components []appstudiov1alpha1.Component
+				for i := 0; i < 5000; i++ {
+					next := fmt.Sprintf("chain-%d", i+1)
+					if i == 4999 {
+						next = "chain-0"
+					}
+					components = append(components, newNudgingComponent(fmt.Sprintf("chain-%d", i), []string{next}))
+				}
+				return components
+			},
+			compName:     "chain-0",
+			nudges:       []string{"chain-1"},
+			errStrPrefix: "cycle detected in build-nudges-ref: chain-0 -> chain-1 -> chain-2",
+			errStrSuffix: "chain-4998 -> chain-4999 -> chain-0",
 		},
 		{
-			name:     "component not found, shouldn't error out",
-			compName: "nudged-component-missing",
-			webhook:  compWebhook,
+			name: "cycle not passing through the admitted component",
+			components: func() []appstudiov1alpha1.Component {
+				return []appstudiov1alpha1.Component{
+					newNudgingComponent("comp-b", []string{"comp-c"}),
+					newNudgingComponent("comp-c", []string{"comp-d"}),
+					newNudgingComponent("comp-d", []string{"comp-b"}),
+				}
+			},
+			compName: "comp-a",
+			nudges:   []string{"comp-b"},
+			errStr:   "cycle detected in build-nudges-ref: comp-b -> comp-c -> comp-d -> comp-b",
+		},
+		{
+			name: "admitted component's new nudges replace the stored ones",
+			components: func() []appstudiov1alpha1.Component {
+				return []appstudiov1alpha1.Component{
+					newNudgingComponent("comp-a", []string{"comp-b"}),
+					newNudgingComponent("comp-b", []string{"comp-c"}),
+					newNudgingComponent("comp-c", nil),
+				}
+			},
+			compName: "comp-c",
+			nudges:   []string{"comp-a"},
+			errStr:   "cycle detected in build-nudges-ref: comp-c -> comp-a -> comp-b -> comp-c",
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			component := &appstudiov1alpha1.Component{}
-			fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: test.compName}, component)
+			s := scheme.Scheme
+			err := appstudiov1alpha1.AddToScheme(s)
+			require.NoError(t, err)
+			components := test.components()
+			objs := make([]client.Object, len(components))
+			for i := range components {
+				objs[i] = &components[i]
+			}
+			countingClient := &CountingClient{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()}
+
+			compWebhook := ComponentWebhook{
+				admissionConfig: admissionConfig{
+					client: countingClient,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
 
-			err := test.webhook.UpdateNudgedComponentStatus(context.Background(), component)
+			_, errs, err := compWebhook.validateBuildNudgesRefGraph(context.Background(), test.nudges, "default", test.compName, "application1", field.NewPath("spec", "build-nudges-ref"))
 			var errStr string
 			if err != nil {
 				errStr = err.Error()
+			} else if len(errs) != 0 {
+				errStr = errs[0].Detail
 			}
-			if errStr != test.errStr {
-				t.Errorf("TestComponentDefault() unexpected error value: want: %v, got: %v", test.errStr, errStr)
+			switch {
+			case test.errStrPrefix != "":
+				assert.True(t, strings.HasPrefix(errStr, test.errStrPrefix), "unexpected error prefix: %v", errStr)
+				assert.True(t, strings.HasSuffix(errStr, test.errStrSuffix), "unexpected error suffix: %v", errStr)
+			default:
+				assert.Equal(t, test.errStr, errStr)
 			}
 
-			// For each nudged component now, retrieve it, and validate that its status was updated
-			if test.errStr != "" {
-				for _, nudgedCompName := range component.Spec.BuildNudgesRef {
-					nudgedComp := &appstudiov1alpha1.Component{}
-					err = fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: nudgedCompName}, nudgedComp)
-					if err != nil {
-						t.Errorf("TestComponentDefault(): unexpected error: %v", err)
-					}
-					if len(nudgedComp.Status.BuildNudgedBy) != 1 {
-						t.Errorf("TestComponentDefault(): status.BuildNudgedBy unexpected length: want: %v, got: %v", 1, len(nudgedComp.Status.BuildNudgedBy))
-					}
-					if nudgedComp.Status.BuildNudgedBy[0] != component.Name {
-						t.Errorf("TestComponentDefault(): status.BuildNudgedBy[0] unexpected value. want: %v, got %v", component.Name, nudgedComp.Status.BuildNudgedBy[0])
-					}
-				}
-
-				// Additional test scenario for this test case:
-				// Adding multiple nudging components to a nudged component
-				if test.name == "multiple nudged components" {
-					// Add another component (which in turn nudges component13) and verify their statuses get updated too
-					newComp := &appstudiov1alpha1.Component{
-						ObjectMeta: v1.ObjectMeta{
-							Name:      "new-comp",
-							Namespace: "default",
-						},
-						TypeMeta: v1.TypeMeta{
-							APIVersion: "appstudio.redhat.com/v1alpha1",
-							Kind:       "Component",
-						},
-						Spec: appstudiov1alpha1.ComponentSpec{
-							ComponentName:  "new-comp",
-							Application:    "application1",
-							BuildNudgesRef: []string{"component13"},
-						},
-					}
-					err = fakeClient.Create(ctx, newComp)
-					require.NoError(t, err)
-
-					// Now call the update function for both resources
-					err = test.webhook.UpdateNudgedComponentStatus(context.Background(), newComp)
-					require.NoError(t, err)
-
-					// Retrieve the component that the new component nudged (component13) and validate its status was updated
-					comp13 := &appstudiov1alpha1.Component{}
-					err := test.webhook.client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "component13"}, comp13)
-					require.NoError(t, err)
-					if len(comp13.Status.BuildNudgedBy) != 2 {
-						t.Errorf("TestComponentDefault(): status.BuildNudgedBy unexpected length: want: %v, got: %v", 2, len(newComp.Status.BuildNudgedBy))
-					}
-					if comp13.Status.BuildNudgedBy[0] != "component10" || comp13.Status.BuildNudgedBy[1] != "new-comp" {
-						t.Errorf("TestComponentDefault(): unexpected status.BuildNudgedBy values. want: %v, got: %v", []string{"component10", "new-comp"}, comp13.Status.BuildNudgedBy)
-					}
-
-				}
-
-			}
+			// The namespace's Components must be retrieved with a single API call
+			assert.Equal(t, 0, countingClient.Gets)
+			assert.Equal(t, 1, countingClient.Lists)
 		})
 	}
 }
 
+// newNudgingComponent returns a Component in the default namespace that nudges the given Components
+func newNudgingComponent(name string, buildNudgesRef []string) appstudiov1alpha1.Component {
+	return appstudiov1alpha1.Component{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		TypeMeta: v1.TypeMeta{
+			APIVersion: "appstudio.redhat.com/v1alpha1",
+			Kind:       "Component",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ComponentName:  name,
+			Application:    "application1",
+			BuildNudgesRef: buildNudgesRef,
+		},
+	}
+}
+
 // setUpComponentsForFakeErrorClient creates a fake controller-runtime Kube client with components to test error scenarios
 func setUpComponentsForFakeErrorClient(t *testing.T) *FakeClient {
 	fakeErrorClient := NewFakeErrorClient(t)
@@ -1236,12 +2740,13 @@ func NewFakeClient(t *testing.T, initObjs ...runtime.Object) client.WithWatch {
 	require.NoError(t, err)
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(s).
+		WithRuntimeObjects(initObjs...).
 		Build()
 
 	return fakeClient
 }
 
-// NewFakeErrorClient returns a fake Kube client whose get method returns an error
+// NewFakeErrorClient returns a fake Kube client whose get and list methods return an error
 // Currently it always returns an error, but can be modified in the future to selectively return errors
 var errNow bool
 
@@ -1254,7 +2759,9 @@ func NewFakeErrorClient(t *testing.T, initObjs ...runtime.Object) *FakeClient {
 		WithScheme(s).
 		WithRuntimeObjects(initObjs...).
 		Build()
-	return &FakeClient{Client: cl, MockGet: func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+	return &FakeClient{Client: cl, MockList: func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+		return fmt.Errorf("some error")
+	}, MockGet: func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
 		// When the fake error client is called against a component with this name, it will error out every other call
 		// This is to help test error scenarios where we the Get operation succeeds sometimes, but not always
 		if key.Name == "alternating-error-comp" {
@@ -1281,3 +2788,27 @@ func (c *FakeClient) Get(ctx context.Context, key types.NamespacedName, obj clie
 	}
 	return c.Client.Get(ctx, key, obj)
 }
+
+func (c *FakeClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if c.MockList != nil {
+		return c.MockList(ctx, list, opts...)
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+// CountingClient is a Kube client that counts the number of Get and List calls made against it
+type CountingClient struct {
+	client.Client
+	Gets  int
+	Lists int
+}
+
+func (c *CountingClient) Get(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+	c.Gets++
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *CountingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.Lists++
+	return c.Client.List(ctx, list, opts...)
+}
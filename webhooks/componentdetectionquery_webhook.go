@@ -0,0 +1,119 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// detectionCompletedCondition is the status condition set to true on a ComponentDetectionQuery once the detection of
+// its components has completed, successfully or not
+const detectionCompletedCondition = "Completed"
+
+// ComponentDetectionQueryWebhook describes the data structure for the ComponentDetectionQuery webhook
+type ComponentDetectionQueryWebhook struct {
+	admissionConfig
+
+	// quotas are the default limits on the number of ComponentDetectionQueries running per namespace
+	quotas quotas
+
+	// allowedGitHosts are the hosts git sources can be hosted on. If empty, any public host is allowed.
+	allowedGitHosts []string
+}
+
+func (w *ComponentDetectionQueryWebhook) Register(mgr ctrl.Manager, log *logr.Logger) error {
+	if err := w.register(mgr, log, componentDetectionQueryWebhookName); err != nil {
+		return err
+	}
+	w.quotas = quotasFromEnv(w.log)
+	w.allowedGitHosts = allowedGitHostsFromEnv()
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&appstudiov1alpha1.ComponentDetectionQuery{}).
+		WithValidator(w).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-appstudio-redhat-com-v1alpha1-componentdetectionquery,mutating=false,failurePolicy=fail,sideEffects=NoneOnDryRun,groups=appstudio.redhat.com,resources=componentdetectionqueries,verbs=create;update,versions=v1alpha1,name=vcomponentdetectionquery.kb.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=componentdetectionqueries,verbs=get;list;watch
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+// The git source is validated as the one of a Component
+func (r *ComponentDetectionQueryWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(componentDetectionQueryWebhookName, createOperation, time.Now(), &err)
+	cdq := obj.(*appstudiov1alpha1.ComponentDetectionQuery)
+	cdqlog := requestLogger(ctx, r.log, "ComponentDetectionQuery", cdq)
+	cdqlog.Info("validating the create request")
+
+	var errs field.ErrorList
+	gitPath := field.NewPath("spec", "git")
+
+	if cdq.Spec.GitSource.URL == "" {
+		errs = append(errs, field.Required(gitPath.Child("url"), "a git repository must be provided to detect its components"))
+	} else {
+		errs = append(errs, validateGitSourceURL(cdq.Spec.GitSource.URL, r.allowedGitHosts, gitPath.Child("url"))...)
+	}
+	errs = append(errs, validateGitSource(&cdq.Spec.GitSource, nil, gitPath)...)
+
+	return r.finish(ctx, cdqlog, "ComponentDetectionQuery", cdq, nil, errs, nil, func() error {
+		return r.quotas.forNamespace(ctx, r.reader(), cdqlog, cdq.Namespace).validateDetectionQueryQuota(ctx, r.client, cdq.Namespace)
+	})
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+// The spec of a ComponentDetectionQuery cannot be changed once its detection has completed, as its results would no
+// longer match it. Until then, only the fields of the git source that changed are validated.
+func (r *ComponentDetectionQueryWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(componentDetectionQueryWebhookName, updateOperation, time.Now(), &err)
+	oldCDQ := oldObj.(*appstudiov1alpha1.ComponentDetectionQuery)
+	newCDQ := newObj.(*appstudiov1alpha1.ComponentDetectionQuery)
+	cdqlog := requestLogger(ctx, r.log, "ComponentDetectionQuery", newCDQ)
+	cdqlog.Info("validating the update request")
+
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if !reflect.DeepEqual(newCDQ.Spec, oldCDQ.Spec) {
+		if meta.IsStatusConditionTrue(oldCDQ.Status.Conditions, detectionCompletedCondition) {
+			errs = append(errs, field.Forbidden(specPath, "cannot be changed once the detection has completed, create a new ComponentDetectionQuery instead"))
+		} else {
+			gitPath := specPath.Child("git")
+			if newCDQ.Spec.GitSource.URL != oldCDQ.Spec.GitSource.URL {
+				errs = append(errs, validateGitSourceURL(newCDQ.Spec.GitSource.URL, r.allowedGitHosts, gitPath.Child("url"))...)
+			}
+			errs = append(errs, validateGitSource(&newCDQ.Spec.GitSource, &oldCDQ.Spec.GitSource, gitPath)...)
+		}
+	}
+
+	return r.finish(ctx, cdqlog, "ComponentDetectionQuery", newCDQ, oldCDQ, errs, nil, nil)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ComponentDetectionQueryWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(componentDetectionQueryWebhookName, deleteOperation, time.Now(), &err)
+	return nil, nil
+}
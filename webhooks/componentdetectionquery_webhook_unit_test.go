@@ -0,0 +1,232 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// newDetectionQuery returns a ComponentDetectionQuery of the given git source, whose detection has completed if
+// completed is set
+func newDetectionQuery(name string, gitSource appstudiov1alpha1.GitSource, completed bool) *appstudiov1alpha1.ComponentDetectionQuery {
+	cdq := &appstudiov1alpha1.ComponentDetectionQuery{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: appstudiov1alpha1.ComponentDetectionQuerySpec{
+			GitSource: gitSource,
+		},
+	}
+	if completed {
+		cdq.Status.Conditions = []v1.Condition{{Type: detectionCompletedCondition, Status: v1.ConditionTrue, Reason: "OK"}}
+	}
+	return cdq
+}
+
+func TestComponentDetectionQueryCreateValidatingWebhook(t *testing.T) {
+	tests := []struct {
+		name            string
+		gitSource       appstudiov1alpha1.GitSource
+		allowedGitHosts []string
+		err             string
+	}{
+		{
+			name:      "valid git source",
+			gitSource: appstudiov1alpha1.GitSource{URL: "https://github.com/devfile-samples/devfile-sample-java-springboot-basic", Revision: "main", Context: "backend"},
+		},
+		{
+			name: "git url is required",
+			err:  "spec.git.url: Required value: a git repository must be provided to detect its components",
+		},
+		{
+			name:      "invalid git url",
+			gitSource: appstudiov1alpha1.GitSource{URL: "badurl"},
+			err:       "spec.git.url: Invalid value: \"badurl\": \"badurl\" is not an http(s), ssh or scp-like git URL",
+		},
+		{
+			name:            "git host not allowed",
+			gitSource:       appstudiov1alpha1.GitSource{URL: "https://gitlab.example.com/org/repo"},
+			allowedGitHosts: []string{"github.com"},
+			err:             "spec.git.url: Invalid value: \"https://gitlab.example.com/org/repo\"",
+		},
+		{
+			name:      "invalid revision and context",
+			gitSource: appstudiov1alpha1.GitSource{URL: "https://github.com/org/repo", Revision: "main..dev", Context: "../backend"},
+			err:       "[spec.git.revision: Invalid value: \"main..dev\"",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cdqWebhook := ComponentDetectionQueryWebhook{
+				admissionConfig: admissionConfig{
+					client: NewFakeClient(t),
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+				allowedGitHosts: test.allowedGitHosts,
+			}
+
+			_, err := cdqWebhook.ValidateCreate(context.Background(), newDetectionQuery("cdq1", test.gitSource, false))
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.True(t, k8sErrors.IsInvalid(err), "expected an Invalid error, got %v", err)
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
+
+func TestComponentDetectionQueryUpdateValidatingWebhook(t *testing.T) {
+	gitSource := appstudiov1alpha1.GitSource{URL: "https://github.com/org/repo"}
+
+	tests := []struct {
+		name      string
+		completed bool
+		update    func(cdq *appstudiov1alpha1.ComponentDetectionQuery)
+		err       string
+	}{
+		{
+			name: "git source can be changed before the detection completed",
+			update: func(cdq *appstudiov1alpha1.ComponentDetectionQuery) {
+				cdq.Spec.GitSource.URL = "https://github.com/org/other-repo"
+				cdq.Spec.GitSource.Revision = "main"
+			},
+		},
+		{
+			name: "changed git source is validated",
+			update: func(cdq *appstudiov1alpha1.ComponentDetectionQuery) {
+				cdq.Spec.GitSource.URL = "badurl"
+				cdq.Spec.GitSource.Context = "/backend"
+			},
+			err: "[spec.git.url: Invalid value: \"badurl\"",
+		},
+		{
+			name:      "spec cannot be changed once the detection completed",
+			completed: true,
+			update: func(cdq *appstudiov1alpha1.ComponentDetectionQuery) {
+				cdq.Spec.GenerateComponentName = true
+			},
+			err: "spec: Forbidden: cannot be changed once the detection has completed, create a new ComponentDetectionQuery instead",
+		},
+		{
+			name:      "metadata can be changed once the detection completed",
+			completed: true,
+			update: func(cdq *appstudiov1alpha1.ComponentDetectionQuery) {
+				cdq.Labels = map[string]string{"team": "backend"}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cdqWebhook := ComponentDetectionQueryWebhook{
+				admissionConfig: admissionConfig{
+					client: NewFakeClient(t),
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+
+			oldCDQ := newDetectionQuery("cdq1", gitSource, test.completed)
+			newCDQ := oldCDQ.DeepCopy()
+			test.update(newCDQ)
+			_, err := cdqWebhook.ValidateUpdate(context.Background(), oldCDQ, newCDQ)
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.True(t, k8sErrors.IsInvalid(err), "expected an Invalid error, got %v", err)
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
+
+func TestComponentDetectionQueryQuota(t *testing.T) {
+	tests := []struct {
+		name        string
+		quotas      quotas
+		annotations map[string]string
+		err         string
+	}{
+		{
+			name: "no quota is enforced by default",
+		},
+		{
+			name:   "query below the configured quota can be created",
+			quotas: quotas{maxDetectionQueries: 3},
+		},
+		{
+			name:   "query above the configured quota cannot be created",
+			quotas: quotas{maxDetectionQueries: 2},
+			err:    "namespace default already runs 2 component detection query(ies), the limit is 2",
+		},
+		{
+			name:        "namespace annotation overrides the configured quota",
+			quotas:      quotas{maxDetectionQueries: 5},
+			annotations: map[string]string{maxDetectionQueriesAnnotation: "1"},
+			err:         "namespace default already runs 2 component detection query(ies), the limit is 1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gitSource := appstudiov1alpha1.GitSource{URL: "https://github.com/org/repo"}
+			fakeClient := NewFakeClient(t,
+				&corev1.Namespace{
+					ObjectMeta: v1.ObjectMeta{
+						Name:        "default",
+						Annotations: test.annotations,
+					},
+				},
+				// Only the queries whose detection has not completed count towards the quota
+				newDetectionQuery("running1", gitSource, false),
+				newDetectionQuery("running2", gitSource, false),
+				newDetectionQuery("completed", gitSource, true),
+			)
+			cdqWebhook := ComponentDetectionQueryWebhook{
+				admissionConfig: admissionConfig{
+					client: fakeClient,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+				quotas: test.quotas,
+			}
+
+			_, err := cdqWebhook.ValidateCreate(context.Background(), newDetectionQuery("cdq1", gitSource, false))
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redhat-appstudio/application-service/pkg/util"
+)
+
+// environmentLabel is the Application label whose productionEnvironment value marks it as a production Application
+const (
+	environmentLabel      = "appstudio.redhat.com/environment"
+	productionEnvironment = "production"
+)
+
+// allowedImageRegistriesFromEnv returns the comma-separated list of registries set in the ALLOWED_IMAGE_REGISTRIES
+// environment variable. An empty list allows any registry.
+func allowedImageRegistriesFromEnv() []string {
+	var registries []string
+	for _, registry := range strings.Split(os.Getenv("ALLOWED_IMAGE_REGISTRIES"), ",") {
+		registry = strings.TrimSuffix(strings.TrimSpace(registry), "/")
+		if registry != "" {
+			registries = append(registries, registry)
+		}
+	}
+	return registries
+}
+
+// validateContainerImage returns an error if image isn't a well-formed image reference, if it isn't hosted in one of
+// allowedRegistries, or if it has no digest while requireDigest is set.
+// An allowed registry can also restrict the repositories of the registry, e.g. quay.io/my-org.
+func validateContainerImage(image string, allowedRegistries []string, requireDigest bool) error {
+	ref, err := util.ParseImageReference(image)
+	if err != nil {
+		return err
+	}
+
+	if len(allowedRegistries) != 0 {
+		allowed := false
+		for _, allowedRegistry := range allowedRegistries {
+			if ref.Registry == allowedRegistry || strings.HasPrefix(ref.Name(), allowedRegistry+"/") {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("image %s is not hosted in an allowed registry, allowed registries are: %s", image, strings.Join(allowedRegistries, ", "))
+		}
+	}
+
+	if requireDigest && ref.Digest == "" {
+		return fmt.Errorf("image %s must be referenced by digest, e.g. %s@sha256:<digest>, as the application is labelled %s=%s", image, ref.Name(), environmentLabel, productionEnvironment)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"os"
+	"regexp"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-appstudio/application-service/pkg/policy"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// quotaRule is the name of the rule limiting the number of Applications and Components
+	quotaRule = "quota"
+
+	// deletionGuardRule is the name of the rule refusing the deletion of Applications that still own resources
+	deletionGuardRule = "deletion-guard"
+
+	// policyWarningReason is the reason of the events recorded on objects admitted despite the violation of a rule
+	// in warn mode
+	policyWarningReason = "PolicyWarning"
+
+	// eventSource is the component recording the events of the webhooks
+	eventSource = "application-service-webhook"
+)
+
+// fieldSubscript matches the list indices and map keys of a field path, e.g. [2] in spec.build-nudges-ref[2]
+var fieldSubscript = regexp.MustCompile(`\[[^\]]*\]`)
+
+// enforcementModesFromEnv returns the enforcement modes of the validation rules set in the ENFORCEMENT_MODES
+// environment variable, as a comma-separated list of rule=mode pairs. Invalid modes are logged and ignored.
+func enforcementModesFromEnv(log logr.Logger) policy.Modes {
+	modes, err := policy.ParseModes(os.Getenv("ENFORCEMENT_MODES"))
+	if err != nil {
+		log.Error(err, "ignoring the ENFORCEMENT_MODES environment variable")
+		return nil
+	}
+	return modes
+}
+
+// enforcer applies the enforcement modes of the validation rules and policies to their violations
+type enforcer struct {
+	log   logr.Logger
+	kind  string
+	modes policy.Modes
+
+	// recorder records the warnings as events on obj once the request is admitted. If nil, no event is recorded.
+	recorder record.EventRecorder
+	obj      runtime.Object
+
+	// warnings are the messages of the violations in warn mode, pending until the request is admitted
+	warnings []string
+}
+
+// newEnforcer loads the policies and returns them along with the enforcer of a request for obj, of the given kind.
+// The enforcement modes set at startup are overridden by the ones of the policies ConfigMap, read from the same watched
+// ConfigMap as the policies, so that changes to them apply without calling the API server. If the policies can't be
+// loaded, e.g. because the ConfigMap is malformed, the error is logged and counted, and the request is decided without
+// them, so that a broken ConfigMap doesn't block every request.
+// No event is recorded for dry-run requests, as the object is not persisted.
+func newEnforcer(ctx context.Context, loader *policy.Loader, modes policy.Modes, recorder record.EventRecorder, log logr.Logger, kind string, obj runtime.Object) (*policy.Set, *enforcer) {
+	policies := &policy.Set{}
+	if loader != nil {
+		loaded, err := loader.Load(ctx)
+		if err != nil {
+			policyLoadErrors.WithLabelValues(kind).Inc()
+			log.Error(err, "unable to load the admission policies, deciding without them")
+		} else {
+			policies = loaded
+		}
+	}
+	if isDryRun(ctx) {
+		recorder = nil
+	}
+	return policies, &enforcer{log: log, kind: kind, modes: modes.Merge(policies.Modes()), recorder: recorder, obj: obj}
+}
+
+// denies returns true if the violation of the given rule must deny the request, i.e. if the rule is in enforce mode.
+// fallback is the mode of the rule when no mode is configured for it, empty meaning enforce.
+// Violations of rules in warn mode are added to the warnings, and recorded as events on the object if the request is
+// admitted, and the ones of rules in audit mode are only logged. Both are counted.
+func (e *enforcer) denies(rule string, fallback policy.Mode, message string, warnings *admission.Warnings) bool {
+	mode := e.modes.Mode(rule, fallback)
+	if mode == policy.ModeEnforce {
+		return true
+	}
+
+	unenforcedViolations.WithLabelValues(e.kind, rule, string(mode)).Inc()
+	e.log.Info("admitting the request despite a violation", "rule", rule, "mode", mode, "violation", message)
+	if mode == policy.ModeWarn {
+		*warnings = append(*warnings, message)
+		e.warnings = append(e.warnings, message)
+	}
+	return false
+}
+
+// recordWarnings records the violations in warn mode as events on the object. It's meant to be called once the
+// request is admitted, so that no event refers to an object that isn't persisted.
+func (e *enforcer) recordWarnings() {
+	if e.recorder == nil {
+		return
+	}
+	for _, message := range e.warnings {
+		e.recorder.Event(e.obj, corev1.EventTypeWarning, policyWarningReason, message)
+	}
+}
+
+// filter returns the field errors that must deny the request. The rule of a field error is its field path without
+// list indices and map keys, e.g. spec.build-nudges-ref or metadata.labels.
+func (e *enforcer) filter(errs field.ErrorList, warnings *admission.Warnings) field.ErrorList {
+	var denying field.ErrorList
+	for _, err := range errs {
+		if e.denies(fieldSubscript.ReplaceAllString(err.Field, ""), "", err.Error(), warnings) {
+			denying = append(denying, err)
+		}
+	}
+	return denying
+}
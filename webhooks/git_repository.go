@@ -0,0 +1,228 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// validateGitRepositoryURL returns an error if rawURL is not an http(s), ssh or scp-like URL naming a repository,
+// i.e. with a host and at least an organization and a repository in its path
+func validateGitRepositoryURL(rawURL string) error {
+	_, err := util.ParseGitURL(rawURL)
+	return err
+}
+
+// validateGitSourceURL returns the violations found in the git source URL at fldPath: it must be a valid git URL,
+// hosted on one of allowedHosts if any, or on a public host otherwise
+func validateGitSourceURL(rawURL string, allowedHosts []string, fldPath *field.Path) field.ErrorList {
+	if err := validateGitRepositoryURL(rawURL); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, rawURL, err.Error())}
+	}
+	var err error
+	if len(allowedHosts) != 0 {
+		err = validateGitRepositoryHost(rawURL, allowedHosts)
+	} else {
+		err = validateGitRepositoryPublicHost(rawURL)
+	}
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, rawURL, err.Error())}
+	}
+	return nil
+}
+
+// validateGitRepositoryBranch returns an error if branch is set but is neither a legal git ref name nor a full commit SHA
+func validateGitRepositoryBranch(branch string) error {
+	if branch == "" || util.IsFullCommitSHA(branch) {
+		return nil
+	}
+	return util.ValidateGitRefName(branch)
+}
+
+// validateGitRepositoryContext returns an error if context is set but is not a clean relative path inside the repository
+func validateGitRepositoryContext(context string) error {
+	if context == "" {
+		return nil
+	}
+	if !isCleanRelativePath(strings.TrimSuffix(context, "/")) {
+		return fmt.Errorf("context %q must be a clean relative path inside the repository", context)
+	}
+	return nil
+}
+
+// validateGitSourceFileURL returns an error if fileURL is set but is neither an http(s) URL nor a clean relative path
+// inside the context of the git source
+func validateGitSourceFileURL(fileURL string) error {
+	if fileURL == "" {
+		return nil
+	}
+	if !strings.Contains(fileURL, "://") {
+		if !isCleanRelativePath(fileURL) {
+			return fmt.Errorf("%q must be an http(s) URL or a clean relative path inside the context", fileURL)
+		}
+		return nil
+	}
+
+	parsedURL, err := url.ParseRequestURI(fileURL)
+	if err != nil {
+		return err
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", parsedURL.Scheme)
+	}
+	if parsedURL.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
+
+// isCleanRelativePath returns true if p is a relative path that is already in its shortest form, and doesn't go up to
+// the parent directory, e.g. folderA/folderB but not /folderA, ../folderA, folderA/../folderB or ./folderA
+func isCleanRelativePath(p string) bool {
+	if path.IsAbs(p) || path.Clean(p) != p || strings.Contains(p, "\\") {
+		return false
+	}
+	return p != ".." && !strings.HasPrefix(p, "../")
+}
+
+// validateGitSource returns the violations found in the revision, context, devfile URL and Dockerfile URL of newSource.
+// If oldSource is set, only the fields that changed since oldSource are validated. Updates of the other fields are
+// validated the same way, so that resources created before a validation was introduced can still be updated as long as
+// the invalid fields are left unchanged.
+func validateGitSource(newSource, oldSource *appstudiov1alpha1.GitSource, fldPath *field.Path) field.ErrorList {
+	if oldSource == nil {
+		oldSource = &appstudiov1alpha1.GitSource{}
+	}
+	var errs field.ErrorList
+
+	if newSource.Revision != oldSource.Revision {
+		if err := validateGitRepositoryBranch(newSource.Revision); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("revision"), newSource.Revision, err.Error()))
+		}
+	}
+	if newSource.Context != oldSource.Context {
+		if err := validateGitRepositoryContext(newSource.Context); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("context"), newSource.Context, err.Error()))
+		}
+	}
+	if newSource.DevfileURL != oldSource.DevfileURL {
+		if err := validateGitSourceFileURL(newSource.DevfileURL); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("devfileUrl"), newSource.DevfileURL, err.Error()))
+		}
+	}
+	if newSource.DockerfileURL != oldSource.DockerfileURL {
+		if err := validateGitSourceFileURL(newSource.DockerfileURL); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("dockerfileUrl"), newSource.DockerfileURL, err.Error()))
+		}
+	}
+	return errs
+}
+
+// defaultAllowedGitHosts are the Git hosts Components can be built from when ALLOWED_GIT_HOSTS isn't set
+var defaultAllowedGitHosts = []string{"github.com", "gitlab.com"}
+
+// allowedGitHostsFromEnv returns the comma-separated list of Git hosts set in the ALLOWED_GIT_HOSTS environment
+// variable, or defaultAllowedGitHosts if it isn't set. The "*" wildcard returns an empty list, which allows any
+// public host.
+func allowedGitHostsFromEnv() []string {
+	var hosts []string
+	for _, host := range strings.Split(os.Getenv("ALLOWED_GIT_HOSTS"), ",") {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "*" {
+			return nil
+		}
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		return defaultAllowedGitHosts
+	}
+	return hosts
+}
+
+// validateGitRepositoryHost returns an error if the host of rawURL is not one of allowedHosts. An allowed host
+// without a port matches any port, while an allowed host with a port only matches that port.
+func validateGitRepositoryHost(rawURL string, allowedHosts []string) error {
+	gitURL, err := util.ParseGitURL(rawURL)
+	if err != nil {
+		return err
+	}
+	for _, allowedHost := range allowedHosts {
+		if gitURL.Host == allowedHost || gitURL.HostWithPort() == allowedHost {
+			return nil
+		}
+	}
+	return fmt.Errorf("git host %s is not allowed, allowed hosts are: %s", gitURL.HostWithPort(), strings.Join(allowedHosts, ", "))
+}
+
+// validateGitRepositoryPublicHost returns an error if the host of rawURL is an IP address or a name that resolves
+// inside the cluster or the node, i.e. localhost, a single-label name or a Kubernetes service name, so that git
+// sources can't target internal endpoints when any host is allowed. Such hosts must be listed in ALLOWED_GIT_HOSTS.
+func validateGitRepositoryPublicHost(rawURL string) error {
+	gitURL, err := util.ParseGitURL(rawURL)
+	if err != nil {
+		return err
+	}
+	host := strings.TrimSuffix(strings.Trim(gitURL.Host, "[]"), ".")
+	labels := strings.Split(host, ".")
+	lastLabel := labels[len(labels)-1]
+	switch {
+	case net.ParseIP(host) != nil || strings.HasPrefix(lastLabel, "0x") || strings.Trim(lastLabel, "0123456789") == "":
+		// Top-level domains are never numeric, so a numeric last label is an IP address in one of its shorthand forms
+		return fmt.Errorf("git host %s is not allowed, IP addresses must be listed in ALLOWED_GIT_HOSTS", gitURL.Host)
+	case len(labels) == 1 || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".svc") || strings.HasSuffix(host, ".cluster.local"):
+		return fmt.Errorf("git host %s is not allowed, internal hosts must be listed in ALLOWED_GIT_HOSTS", gitURL.Host)
+	}
+	return nil
+}
+
+// canonicalGitRepositoryURL returns the canonical form of rawURL, which is the same for all the URLs of a repository.
+// URLs that can't be parsed are returned unchanged.
+func canonicalGitRepositoryURL(rawURL string) string {
+	gitURL, err := util.ParseGitURL(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return gitURL.Canonical()
+}
+
+// normalizeGitRepositoryURL returns rawURL with a lower case host stripped of its "www." prefix, and a path stripped of
+// its trailing slashes and ".git" suffix, so that URLs of the same repository compare equal.
+// URLs that can't be parsed are returned unchanged.
+func normalizeGitRepositoryURL(rawURL string) string {
+	repoURL, err := url.ParseRequestURI(rawURL)
+	if err != nil || repoURL.Host == "" {
+		return rawURL
+	}
+
+	repoURL.Host = strings.TrimPrefix(strings.ToLower(repoURL.Host), "www.")
+	repoPath := strings.TrimRight(repoURL.Path, "/")
+	repoPath = strings.TrimSuffix(repoPath, ".git")
+	repoURL.Path = strings.TrimRight(repoPath, "/")
+	repoURL.RawPath = ""
+	return repoURL.String()
+}
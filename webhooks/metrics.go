@@ -0,0 +1,215 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The names of the webhooks and operations in the metrics labels
+const (
+	applicationWebhookName             = "application"
+	componentWebhookName               = "component"
+	componentDetectionQueryWebhookName = "componentdetectionquery"
+	snapshotWebhookName                = "snapshot"
+
+	createOperation = "create"
+	updateOperation = "update"
+	deleteOperation = "delete"
+)
+
+// The outcomes of admission requests in the metrics labels
+const (
+	allowedOutcome = "allowed"
+	deniedOutcome  = "denied"
+	errorOutcome   = "error"
+)
+
+// The reasons of admission decisions in the metrics labels, on top of the quotaRule and deletionGuardRule.
+// Allowed requests have no reason.
+const (
+	invalidReason  = "invalid"
+	policyReason   = "policy"
+	internalReason = "internal"
+)
+
+var (
+	// admissionRequests counts the admission decisions of the validating webhooks
+	admissionRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "application_service_webhook_admission_requests_total",
+			Help: "Number of admission requests handled by the validating webhooks, by webhook, operation, outcome and reason",
+		},
+		[]string{"webhook", "operation", "outcome", "reason"},
+	)
+
+	// admissionDuration measures the time the validating webhooks take to decide
+	admissionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "application_service_webhook_admission_duration_seconds",
+			Help:    "Time taken by the validating webhooks to handle an admission request, by webhook, operation and outcome",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		},
+		[]string{"webhook", "operation", "outcome"},
+	)
+
+	// apiRequestDuration measures the Kubernetes API calls made by the webhooks, e.g. listing the Components to walk
+	// the build-nudges-ref graph or getting the Application of a Component
+	apiRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "application_service_webhook_api_request_duration_seconds",
+			Help:    "Time taken by the Kubernetes API calls made by the webhooks, by webhook, verb, kind and outcome",
+			Buckets: []float64{0.0005, 0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+		},
+		[]string{"webhook", "verb", "kind", "outcome"},
+	)
+
+	// unenforcedViolations counts the violations that didn't deny a request, as their rule is in warn or audit mode
+	unenforcedViolations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "application_service_webhook_unenforced_violations_total",
+			Help: "Number of violations of validation rules in warn or audit mode, which would have denied a request in enforce mode",
+		},
+		[]string{"kind", "rule", "mode"},
+	)
+
+	// policyLoadErrors counts the requests decided without the admission policies, as they couldn't be loaded
+	policyLoadErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "application_service_webhook_policy_load_errors_total",
+			Help: "Number of admission requests decided without the admission policies, as their ConfigMap couldn't be read or is invalid",
+		},
+		[]string{"kind"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(admissionRequests, admissionDuration, apiRequestDuration, unenforcedViolations, policyLoadErrors)
+}
+
+// denialError is an error denying an admission request for a given reason, as opposed to an error preventing the
+// webhook from taking a decision
+type denialError struct {
+	reason string
+	err    error
+}
+
+func (e *denialError) Error() string {
+	return e.err.Error()
+}
+
+func (e *denialError) Unwrap() error {
+	return e.err
+}
+
+// deny marks err as the denial of an admission request for the given reason
+func deny(reason string, err error) error {
+	return &denialError{reason: reason, err: err}
+}
+
+// isDenial returns true if err denies an admission request, false if it prevented the webhook from taking a decision
+func isDenial(err error) bool {
+	var denial *denialError
+	return k8sErrors.IsInvalid(err) || errors.As(err, &denial)
+}
+
+// observeAdmission records the outcome and duration of an admission request handled by a validating webhook since
+// start. It's meant to be deferred with a pointer to the named error returned by the webhook.
+func observeAdmission(webhook string, operation string, start time.Time, err *error) {
+	outcome, reason := allowedOutcome, ""
+	var denial *denialError
+	switch {
+	case *err == nil:
+	case k8sErrors.IsInvalid(*err):
+		outcome, reason = deniedOutcome, invalidReason
+	case errors.As(*err, &denial):
+		outcome, reason = deniedOutcome, denial.reason
+	default:
+		outcome, reason = errorOutcome, internalReason
+	}
+
+	admissionRequests.WithLabelValues(webhook, operation, outcome, reason).Inc()
+	admissionDuration.WithLabelValues(webhook, operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+// instrumentedReader is a reader measuring the duration of the Get and List calls made by a webhook
+type instrumentedReader struct {
+	client.Reader
+	scheme  *runtime.Scheme
+	webhook string
+}
+
+// newInstrumentedReader returns a reader measuring the duration of the Get and List calls made by the given webhook.
+// scheme maps the objects read to their kind.
+func newInstrumentedReader(r client.Reader, scheme *runtime.Scheme, webhook string) client.Reader {
+	return &instrumentedReader{Reader: r, scheme: scheme, webhook: webhook}
+}
+
+func (r *instrumentedReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	start := time.Now()
+	err := r.Reader.Get(ctx, key, obj, opts...)
+	r.observe("get", obj, start, err)
+	return err
+}
+
+func (r *instrumentedReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	start := time.Now()
+	err := r.Reader.List(ctx, list, opts...)
+	r.observe("list", list, start, err)
+	return err
+}
+
+// observe records the duration of an API call on obj since start. Not found errors are successful lookups.
+func (r *instrumentedReader) observe(verb string, obj runtime.Object, start time.Time, err error) {
+	kind := "unknown"
+	if gvk, gvkErr := apiutil.GVKForObject(obj, r.scheme); gvkErr == nil {
+		kind = gvk.Kind
+	}
+	outcome := "success"
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		outcome = "error"
+	}
+	apiRequestDuration.WithLabelValues(r.webhook, verb, kind, outcome).Observe(time.Since(start).Seconds())
+}
+
+// instrumentedClient is a client measuring the duration of the Get and List calls made by a webhook
+type instrumentedClient struct {
+	client.Client
+	reader client.Reader
+}
+
+// newInstrumentedClient returns a client measuring the duration of the Get and List calls made by the given webhook
+func newInstrumentedClient(c client.Client, webhook string) client.Client {
+	return &instrumentedClient{Client: c, reader: newInstrumentedReader(c, c.Scheme(), webhook)}
+}
+
+func (c *instrumentedClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return c.reader.Get(ctx, key, obj, opts...)
+}
+
+func (c *instrumentedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.reader.List(ctx, list, opts...)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redhat-appstudio/application-service/pkg/policy"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// policyLoaderFromEnv returns the loader of the admission policies stored in the policy.ConfigMapName ConfigMap of the
+// operator's namespace, set in the POD_NAMESPACE environment variable. It returns nil if the namespace isn't set.
+// The ConfigMap is read from a cache that only watches it, started by mgr, so that admission requests neither wait
+// on nor depend on the API server to load the policies.
+func policyLoaderFromEnv(mgr ctrl.Manager) (*policy.Loader, error) {
+	namespace := os.Getenv("POD_NAMESPACE")
+	if namespace == "" {
+		return nil, nil
+	}
+
+	configMapCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		HTTPClient: mgr.GetHTTPClient(),
+		Scheme:     mgr.GetScheme(),
+		Mapper:     mgr.GetRESTMapper(),
+		Namespaces: []string{namespace},
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.name", policy.ConfigMapName)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the cache of the policies ConfigMap: %w", err)
+	}
+	// Start watching the ConfigMap along with the cache, rather than on the first admission request
+	if _, err := configMapCache.GetInformer(context.Background(), &corev1.ConfigMap{}); err != nil {
+		return nil, fmt.Errorf("unable to watch the policies ConfigMap: %w", err)
+	}
+	if err := mgr.Add(configMapCache); err != nil {
+		return nil, err
+	}
+	return policy.NewLoader(configMapCache, namespace, policy.ConfigMapName), nil
+}
+
+// evaluatePolicies returns an error naming every admission policy that denies the creation or update of obj.
+// oldObj is nil on creation. Violations of policies in warn mode are added to the warnings instead.
+func evaluatePolicies(ctx context.Context, policies *policy.Set, e *enforcer, c client.Reader, operation string, obj, oldObj client.Object, warnings *admission.Warnings) error {
+	if policies.Len() == 0 {
+		return nil
+	}
+
+	input := policy.Input{
+		Kind:      e.kind,
+		Operation: operation,
+		Object:    obj,
+	}
+	if oldObj != nil {
+		input.OldObject = oldObj
+	}
+	var namespace corev1.Namespace
+	if err := c.Get(ctx, types.NamespacedName{Name: obj.GetNamespace()}, &namespace); err == nil {
+		input.Namespace = &namespace
+	}
+	if req, err := admission.RequestFromContext(ctx); err == nil {
+		input.UserInfo = req.UserInfo
+	}
+
+	violations, err := policies.Evaluate(input)
+	if err != nil {
+		return err
+	}
+	var messages []string
+	for _, violation := range violations {
+		if e.denies(violation.Policy, violation.Mode, violation.String(), warnings) {
+			messages = append(messages, violation.String())
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return deny(policyReason, fmt.Errorf("%s %s was denied: %s", strings.ToLower(e.kind), obj.GetName(), strings.Join(messages, "; ")))
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Namespace annotations overriding the quotas configured for the webhooks
+const (
+	maxApplicationsAnnotation             = "appstudio.redhat.com/max-applications"
+	maxComponentsAnnotation               = "appstudio.redhat.com/max-components"
+	maxComponentsPerApplicationAnnotation = "appstudio.redhat.com/max-components-per-application"
+	maxDetectionQueriesAnnotation         = "appstudio.redhat.com/max-concurrent-detection-queries"
+)
+
+// quotas holds the maximum number of Applications and Components a namespace may contain, and the maximum number of
+// ComponentDetectionQueries it may run concurrently. A limit of 0 means unlimited.
+type quotas struct {
+	maxApplications             int
+	maxComponents               int
+	maxComponentsPerApplication int
+	maxDetectionQueries         int
+}
+
+// quotasFromEnv returns the default quotas configured through the MAX_APPLICATIONS_PER_NAMESPACE,
+// MAX_COMPONENTS_PER_NAMESPACE, MAX_COMPONENTS_PER_APPLICATION and MAX_CONCURRENT_DETECTION_QUERIES_PER_NAMESPACE
+// environment variables
+func quotasFromEnv(log logr.Logger) quotas {
+	return quotas{
+		maxApplications:             parseQuota(log, "MAX_APPLICATIONS_PER_NAMESPACE", os.Getenv("MAX_APPLICATIONS_PER_NAMESPACE")),
+		maxComponents:               parseQuota(log, "MAX_COMPONENTS_PER_NAMESPACE", os.Getenv("MAX_COMPONENTS_PER_NAMESPACE")),
+		maxComponentsPerApplication: parseQuota(log, "MAX_COMPONENTS_PER_APPLICATION", os.Getenv("MAX_COMPONENTS_PER_APPLICATION")),
+		maxDetectionQueries:         parseQuota(log, "MAX_CONCURRENT_DETECTION_QUERIES_PER_NAMESPACE", os.Getenv("MAX_CONCURRENT_DETECTION_QUERIES_PER_NAMESPACE")),
+	}
+}
+
+// parseQuota returns the limit set in value, or 0 (unlimited) if value is empty or not a positive integer
+func parseQuota(log logr.Logger, name string, value string) int {
+	if value == "" {
+		return 0
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		log.Error(err, fmt.Sprintf("ignoring invalid quota %s=%q", name, value))
+		return 0
+	}
+	return limit
+}
+
+// forNamespace returns the quotas of the given namespace: the limits set in the namespace annotations override the
+// default ones. If the namespace can't be read, the default quotas are returned.
+func (q quotas) forNamespace(ctx context.Context, c client.Reader, log logr.Logger, namespace string) quotas {
+	var ns corev1.Namespace
+	err := c.Get(ctx, types.NamespacedName{Name: namespace}, &ns)
+	if err != nil {
+		log.Error(err, "unable to get the namespace, using the default quotas")
+		return q
+	}
+
+	if value, ok := ns.Annotations[maxApplicationsAnnotation]; ok {
+		q.maxApplications = parseQuota(log, maxApplicationsAnnotation, value)
+	}
+	if value, ok := ns.Annotations[maxComponentsAnnotation]; ok {
+		q.maxComponents = parseQuota(log, maxComponentsAnnotation, value)
+	}
+	if value, ok := ns.Annotations[maxComponentsPerApplicationAnnotation]; ok {
+		q.maxComponentsPerApplication = parseQuota(log, maxComponentsPerApplicationAnnotation, value)
+	}
+	if value, ok := ns.Annotations[maxDetectionQueriesAnnotation]; ok {
+		q.maxDetectionQueries = parseQuota(log, maxDetectionQueriesAnnotation, value)
+	}
+	return q
+}
+
+// validateApplicationQuota returns an error if the namespace already contains the maximum number of Applications
+func (q quotas) validateApplicationQuota(ctx context.Context, c client.Client, namespace string) error {
+	if q.maxApplications == 0 {
+		return nil
+	}
+
+	var applicationList appstudiov1alpha1.ApplicationList
+	err := c.List(ctx, &applicationList, client.InNamespace(namespace))
+	if err != nil {
+		return err
+	}
+	if count := len(applicationList.Items); count >= q.maxApplications {
+		return deny(quotaRule, fmt.Errorf("namespace %s already contains %d application(s), the limit is %d", namespace, count, q.maxApplications))
+	}
+	return nil
+}
+
+// validateComponentQuota returns an error if the namespace, or the given Application, already contains the maximum
+// number of Components
+func (q quotas) validateComponentQuota(ctx context.Context, c client.Client, namespace string, applicationName string) error {
+	if q.maxComponents == 0 && q.maxComponentsPerApplication == 0 {
+		return nil
+	}
+
+	var componentList appstudiov1alpha1.ComponentList
+	err := c.List(ctx, &componentList, client.InNamespace(namespace))
+	if err != nil {
+		return err
+	}
+	if count := len(componentList.Items); q.maxComponents != 0 && count >= q.maxComponents {
+		return deny(quotaRule, fmt.Errorf("namespace %s already contains %d component(s), the limit is %d", namespace, count, q.maxComponents))
+	}
+
+	if q.maxComponentsPerApplication == 0 || applicationName == "" {
+		return nil
+	}
+	count := 0
+	for _, component := range componentList.Items {
+		if component.Spec.Application == applicationName {
+			count++
+		}
+	}
+	if count >= q.maxComponentsPerApplication {
+		return deny(quotaRule, fmt.Errorf("application %s already contains %d component(s), the limit is %d", applicationName, count, q.maxComponentsPerApplication))
+	}
+	return nil
+}
+
+// validateDetectionQueryQuota returns an error if the namespace already runs the maximum number of
+// ComponentDetectionQueries, i.e. of queries whose detection has not completed yet
+func (q quotas) validateDetectionQueryQuota(ctx context.Context, c client.Client, namespace string) error {
+	if q.maxDetectionQueries == 0 {
+		return nil
+	}
+
+	var detectionQueryList appstudiov1alpha1.ComponentDetectionQueryList
+	err := c.List(ctx, &detectionQueryList, client.InNamespace(namespace))
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, detectionQuery := range detectionQueryList.Items {
+		if !meta.IsStatusConditionTrue(detectionQuery.Status.Conditions, detectionCompletedCondition) {
+			count++
+		}
+	}
+	if count >= q.maxDetectionQueries {
+		return deny(quotaRule, fmt.Errorf("namespace %s already runs %d component detection query(ies), the limit is %d", namespace, count, q.maxDetectionQueries))
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SnapshotWebhook describes the data structure for the Snapshot webhook
+type SnapshotWebhook struct {
+	admissionConfig
+
+	// allowedImageRegistries are the registries container images can be hosted in. If empty, any registry is allowed.
+	allowedImageRegistries []string
+
+	// requireImageDigestInProduction requires the container images of the Snapshots of production Applications to be
+	// referenced by digest
+	requireImageDigestInProduction bool
+}
+
+func (w *SnapshotWebhook) Register(mgr ctrl.Manager, log *logr.Logger) error {
+	if err := w.register(mgr, log, snapshotWebhookName); err != nil {
+		return err
+	}
+	w.allowedImageRegistries = allowedImageRegistriesFromEnv()
+	w.requireImageDigestInProduction = os.Getenv("REQUIRE_IMAGE_DIGEST_IN_PRODUCTION") == "true"
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&appstudiov1alpha1.Snapshot{}).
+		WithValidator(w).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-appstudio-redhat-com-v1alpha1-snapshot,mutating=false,failurePolicy=fail,sideEffects=NoneOnDryRun,groups=appstudio.redhat.com,resources=snapshots,verbs=create;update,versions=v1alpha1,name=vsnapshot.kb.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=components,verbs=get;list;watch
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+// The Snapshot must reference an existing Application, and each of its components a distinct Component of that
+// Application, along with a valid container image.
+func (r *SnapshotWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(snapshotWebhookName, createOperation, time.Now(), &err)
+	snapshot := obj.(*appstudiov1alpha1.Snapshot)
+	snapshotlog := requestLogger(ctx, r.log, "Snapshot", snapshot)
+	snapshotlog.Info("validating the create request")
+
+	var errs field.ErrorList
+	var warnings admission.Warnings
+	specPath := field.NewPath("spec")
+
+	var application *appstudiov1alpha1.Application
+	if snapshot.Spec.Application == "" {
+		errs = append(errs, field.Required(specPath.Child("application"), "an application must be provided when creating a Snapshot"))
+	} else {
+		application = &appstudiov1alpha1.Application{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: snapshot.Namespace, Name: snapshot.Spec.Application}, application)
+		if err != nil {
+			if !k8sErrors.IsNotFound(err) {
+				return nil, err
+			}
+			errs = append(errs, field.NotFound(specPath.Child("application"), snapshot.Spec.Application))
+			application = nil
+		}
+	}
+
+	componentErrs, componentWarnings, err := r.validateSnapshotComponents(ctx, snapshot, application, specPath.Child("components"))
+	if err != nil {
+		return nil, err
+	}
+	errs = append(errs, componentErrs...)
+	warnings = append(warnings, componentWarnings...)
+
+	return r.finish(ctx, snapshotlog, "Snapshot", snapshot, nil, errs, warnings, nil)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+// The spec of a Snapshot is immutable, as it records the state of an Application at a point in time
+func (r *SnapshotWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(snapshotWebhookName, updateOperation, time.Now(), &err)
+	oldSnapshot := oldObj.(*appstudiov1alpha1.Snapshot)
+	newSnapshot := newObj.(*appstudiov1alpha1.Snapshot)
+	snapshotlog := requestLogger(ctx, r.log, "Snapshot", newSnapshot)
+	snapshotlog.Info("validating the update request")
+
+	var errs field.ErrorList
+	if !reflect.DeepEqual(newSnapshot.Spec, oldSnapshot.Spec) {
+		errs = append(errs, field.Forbidden(field.NewPath("spec"), "cannot be changed once the Snapshot is created, create a new Snapshot instead"))
+	}
+
+	return r.finish(ctx, snapshotlog, "Snapshot", newSnapshot, oldSnapshot, errs, nil, nil)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *SnapshotWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (_ admission.Warnings, err error) {
+	defer observeAdmission(snapshotWebhookName, deleteOperation, time.Now(), &err)
+	return nil, nil
+}
+
+// validateSnapshotComponents returns a violation for every component of the Snapshot that is a duplicate, that isn't
+// a Component of application, or whose container image is invalid. Images that aren't referenced by digest are
+// rejected if application is a production one and the digest is required, and returned as warnings otherwise.
+// application is nil if it doesn't exist, in which case the components are not matched against it.
+// The returned error is only set if the Components couldn't be listed.
+func (r *SnapshotWebhook) validateSnapshotComponents(ctx context.Context, snapshot *appstudiov1alpha1.Snapshot, application *appstudiov1alpha1.Application, fldPath *field.Path) (field.ErrorList, admission.Warnings, error) {
+	applications := map[string]string{}
+	if application != nil && len(snapshot.Spec.Components) != 0 {
+		var componentList appstudiov1alpha1.ComponentList
+		if err := r.client.List(ctx, &componentList, client.InNamespace(snapshot.Namespace)); err != nil {
+			return nil, nil, err
+		}
+		for _, comp := range componentList.Items {
+			applications[comp.Name] = comp.Spec.Application
+		}
+	}
+	requireDigest := r.requireImageDigestInProduction && application != nil && application.Labels[environmentLabel] == productionEnvironment
+
+	var errs field.ErrorList
+	var warnings admission.Warnings
+	var seen []string
+	for i, snapshotComponent := range snapshot.Spec.Components {
+		namePath := fldPath.Index(i).Child("name")
+		switch {
+		case snapshotComponent.Name == "":
+			errs = append(errs, field.Required(namePath, "the name of the component must be provided"))
+		case util.StrInList(snapshotComponent.Name, seen):
+			errs = append(errs, field.Duplicate(namePath, snapshotComponent.Name))
+		case application == nil:
+			// The components can't be matched against a missing Application
+		case applications[snapshotComponent.Name] == "":
+			errs = append(errs, field.NotFound(namePath, snapshotComponent.Name))
+		case applications[snapshotComponent.Name] != application.Name:
+			errs = append(errs, field.Invalid(namePath, snapshotComponent.Name, fmt.Sprintf("component %s belongs to application %s, not to application %s", snapshotComponent.Name, applications[snapshotComponent.Name], application.Name)))
+		}
+		seen = append(seen, snapshotComponent.Name)
+
+		imagePath := fldPath.Index(i).Child("containerImage")
+		if snapshotComponent.ContainerImage == "" {
+			errs = append(errs, field.Required(imagePath, "the container image of the component must be provided"))
+			continue
+		}
+		if err := validateContainerImage(snapshotComponent.ContainerImage, r.allowedImageRegistries, requireDigest); err != nil {
+			errs = append(errs, field.Invalid(imagePath, snapshotComponent.ContainerImage, err.Error()))
+		} else if ref, _ := util.ParseImageReference(snapshotComponent.ContainerImage); ref.Digest == "" {
+			warnings = append(warnings, fmt.Sprintf("image %s of component %s is not referenced by digest, the Snapshot may not be reproducible", snapshotComponent.ContainerImage, snapshotComponent.Name))
+		}
+	}
+	return errs, warnings, nil
+}
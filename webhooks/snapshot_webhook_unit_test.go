@@ -0,0 +1,227 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	pinnedImage   = "quay.io/org/image@sha256:4b825dc642cb6eb9a060e54bf8d69288fbee4904b825dc642cb6eb9a060e54bf"
+	unpinnedImage = "quay.io/org/image:latest"
+)
+
+// newComponent returns a Component of the given Application
+func newComponent(name string, application string) *appstudiov1alpha1.Component {
+	return &appstudiov1alpha1.Component{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ComponentName: name,
+			Application:   application,
+		},
+	}
+}
+
+// newSnapshot returns a Snapshot of the given Application and components
+func newSnapshot(application string, components ...appstudiov1alpha1.SnapshotComponent) *appstudiov1alpha1.Snapshot {
+	return &appstudiov1alpha1.Snapshot{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "snapshot1",
+			Namespace: "default",
+		},
+		Spec: appstudiov1alpha1.SnapshotSpec{
+			Application: application,
+			Components:  components,
+		},
+	}
+}
+
+func TestSnapshotCreateValidatingWebhook(t *testing.T) {
+	productionApplication := &appstudiov1alpha1.Application{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "production-application",
+			Namespace: "default",
+			Labels:    map[string]string{environmentLabel: productionEnvironment},
+		},
+	}
+	fakeClient := NewFakeClient(t,
+		&appstudiov1alpha1.Application{ObjectMeta: v1.ObjectMeta{Name: "application1", Namespace: "default"}},
+		productionApplication,
+		newComponent("component1", "application1"),
+		newComponent("component2", "application1"),
+		newComponent("component3", "application2"),
+		newComponent("component4", productionApplication.Name),
+	)
+
+	tests := []struct {
+		name                   string
+		snapshot               *appstudiov1alpha1.Snapshot
+		allowedImageRegistries []string
+		requireDigest          bool
+		warnings               admission.Warnings
+		err                    string
+	}{
+		{
+			name: "valid snapshot",
+			snapshot: newSnapshot("application1",
+				appstudiov1alpha1.SnapshotComponent{Name: "component1", ContainerImage: pinnedImage},
+				appstudiov1alpha1.SnapshotComponent{Name: "component2", ContainerImage: pinnedImage}),
+		},
+		{
+			name:     "images not referenced by digest are returned as warnings",
+			snapshot: newSnapshot("application1", appstudiov1alpha1.SnapshotComponent{Name: "component1", ContainerImage: unpinnedImage}),
+			warnings: admission.Warnings{"image quay.io/org/image:latest of component component1 is not referenced by digest, the Snapshot may not be reproducible"},
+		},
+		{
+			name:     "application is required",
+			snapshot: newSnapshot(""),
+			err:      "spec.application: Required value: an application must be provided when creating a Snapshot",
+		},
+		{
+			name:     "application must exist",
+			snapshot: newSnapshot("missing-application", appstudiov1alpha1.SnapshotComponent{Name: "component1", ContainerImage: pinnedImage}),
+			err:      "spec.application: Not found: \"missing-application\"",
+		},
+		{
+			name:     "components must exist",
+			snapshot: newSnapshot("application1", appstudiov1alpha1.SnapshotComponent{Name: "missing-component", ContainerImage: pinnedImage}),
+			err:      "spec.components[0].name: Not found: \"missing-component\"",
+		},
+		{
+			name:     "components must belong to the application",
+			snapshot: newSnapshot("application1", appstudiov1alpha1.SnapshotComponent{Name: "component3", ContainerImage: pinnedImage}),
+			err:      "spec.components[0].name: Invalid value: \"component3\": component component3 belongs to application application2, not to application application1",
+		},
+		{
+			name: "component names must be unique",
+			snapshot: newSnapshot("application1",
+				appstudiov1alpha1.SnapshotComponent{Name: "component1", ContainerImage: pinnedImage},
+				appstudiov1alpha1.SnapshotComponent{Name: "component1", ContainerImage: pinnedImage}),
+			err: "spec.components[1].name: Duplicate value: \"component1\"",
+		},
+		{
+			name:     "names and images are required",
+			snapshot: newSnapshot("application1", appstudiov1alpha1.SnapshotComponent{}),
+			err:      "[spec.components[0].name: Required value: the name of the component must be provided, spec.components[0].containerImage: Required value: the container image of the component must be provided]",
+		},
+		{
+			name:     "images must be valid references",
+			snapshot: newSnapshot("application1", appstudiov1alpha1.SnapshotComponent{Name: "component1", ContainerImage: "quay.io/Org/image"}),
+			err:      "spec.components[0].containerImage: Invalid value: \"quay.io/Org/image\"",
+		},
+		{
+			name:                   "images must be hosted in an allowed registry",
+			snapshot:               newSnapshot("application1", appstudiov1alpha1.SnapshotComponent{Name: "component1", ContainerImage: pinnedImage}),
+			allowedImageRegistries: []string{"registry.redhat.io"},
+			err:                    "is not hosted in an allowed registry, allowed registries are: registry.redhat.io",
+		},
+		{
+			name:          "images of production applications must be referenced by digest",
+			snapshot:      newSnapshot(productionApplication.Name, appstudiov1alpha1.SnapshotComponent{Name: "component4", ContainerImage: unpinnedImage}),
+			requireDigest: true,
+			err:           "image quay.io/org/image:latest must be referenced by digest",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			snapshotWebhook := SnapshotWebhook{
+				admissionConfig: admissionConfig{
+					client: fakeClient,
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+				allowedImageRegistries:         test.allowedImageRegistries,
+				requireImageDigestInProduction: test.requireDigest,
+			}
+
+			warnings, err := snapshotWebhook.ValidateCreate(context.Background(), test.snapshot)
+			if test.err == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, test.warnings, warnings)
+			} else {
+				assert.True(t, k8sErrors.IsInvalid(err), "expected an Invalid error, got %v", err)
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
+
+func TestSnapshotUpdateValidatingWebhook(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(snapshot *appstudiov1alpha1.Snapshot)
+		err    string
+	}{
+		{
+			name: "metadata can be changed",
+			update: func(snapshot *appstudiov1alpha1.Snapshot) {
+				snapshot.Labels = map[string]string{"test.appstudio.openshift.io/status": "passed"}
+			},
+		},
+		{
+			name: "components cannot be changed",
+			update: func(snapshot *appstudiov1alpha1.Snapshot) {
+				snapshot.Spec.Components[0].ContainerImage = unpinnedImage
+			},
+			err: "spec: Forbidden: cannot be changed once the Snapshot is created, create a new Snapshot instead",
+		},
+		{
+			name: "application cannot be changed",
+			update: func(snapshot *appstudiov1alpha1.Snapshot) {
+				snapshot.Spec.Application = "application2"
+			},
+			err: "spec: Forbidden: cannot be changed once the Snapshot is created, create a new Snapshot instead",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			snapshotWebhook := SnapshotWebhook{
+				admissionConfig: admissionConfig{
+					client: NewFakeClient(t),
+					log: zap.New(zap.UseFlagOptions(&zap.Options{
+						Development: true,
+						TimeEncoder: zapcore.ISO8601TimeEncoder,
+					})),
+				},
+			}
+
+			oldSnapshot := newSnapshot("application1", appstudiov1alpha1.SnapshotComponent{Name: "component1", ContainerImage: pinnedImage})
+			newSnapshot := oldSnapshot.DeepCopy()
+			test.update(newSnapshot)
+			_, err := snapshotWebhook.ValidateUpdate(context.Background(), oldSnapshot, newSnapshot)
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.True(t, k8sErrors.IsInvalid(err), "expected an Invalid error, got %v", err)
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
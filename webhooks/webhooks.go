@@ -16,11 +16,118 @@
 package webhooks
 
 import (
+	"context"
+
+	"github.com/go-logr/logr"
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/konflux-ci/operator-toolkit/webhook"
+	"github.com/redhat-appstudio/application-service/pkg/policy"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // EnabledWebhooks is a slice containing references to all the webhooks that have to be registered
 var EnabledWebhooks = []webhook.Webhook{
 	&ApplicationWebhook{},
 	&ComponentWebhook{},
+	&ComponentDetectionQueryWebhook{},
+	&SnapshotWebhook{},
+}
+
+// admissionConfig is the configuration shared by the validating webhooks, which embed it
+type admissionConfig struct {
+	client client.Client
+	log    logr.Logger
+
+	// apiReader reads the objects the manager doesn't cache, e.g. Namespaces, directly from the API server, so that
+	// the webhooks don't start informers on them. If nil, client is used.
+	apiReader client.Reader
+
+	// policies loads the organization-specific admission policies. If nil, no policy is evaluated.
+	policies *policy.Loader
+
+	// recorder records the violations of rules in warn mode as events on the admitted objects. If nil, no event is
+	// recorded.
+	recorder record.EventRecorder
+
+	// enforcementModes are the enforcement modes of the validation rules set at startup, enforce by default
+	enforcementModes policy.Modes
+}
+
+// register sets up the configuration of the webhook of the given name from the manager and the environment
+func (c *admissionConfig) register(mgr ctrl.Manager, log *logr.Logger, name string) error {
+	c.log = log.WithName(name)
+	c.client = newInstrumentedClient(mgr.GetClient(), name)
+	c.apiReader = newInstrumentedReader(mgr.GetAPIReader(), mgr.GetScheme(), name)
+	policies, err := policyLoaderFromEnv(mgr)
+	if err != nil {
+		return err
+	}
+	c.policies = policies
+	c.enforcementModes = enforcementModesFromEnv(c.log)
+	c.recorder = mgr.GetEventRecorderFor(eventSource)
+	return nil
+}
+
+// reader returns the reader of the objects the manager doesn't cache
+func (c *admissionConfig) reader() client.Reader {
+	if c.apiReader == nil {
+		return c.client
+	}
+	return c.apiReader
+}
+
+// finish decides on the creation or update of obj, of the given kind, once its fields are validated. oldObj is nil on
+// creation. The field errors are filtered by their enforcement modes, and the remaining ones are reported in a single
+// Invalid error, with their paths. The admission policies are then evaluated, followed by the quota, if any, whose
+// error is subject to the quotaRule enforcement mode when it's a denial. The violations in warn mode are only recorded
+// as events once the request is admitted.
+func (c *admissionConfig) finish(ctx context.Context, log logr.Logger, kind string, obj, oldObj client.Object, errs field.ErrorList, warnings admission.Warnings, quota func() error) (admission.Warnings, error) {
+	policies, enforcer := newEnforcer(ctx, c.policies, c.enforcementModes, c.recorder, log, kind, obj)
+	if errs = enforcer.filter(errs, &warnings); len(errs) != 0 {
+		return warnings, newInvalidError(kind, obj.GetName(), errs)
+	}
+	operation := "CREATE"
+	if oldObj != nil {
+		operation = "UPDATE"
+	}
+	if err := evaluatePolicies(ctx, policies, enforcer, c.reader(), operation, obj, oldObj, &warnings); err != nil {
+		return warnings, err
+	}
+
+	if quota != nil {
+		if err := quota(); err != nil && (!isDenial(err) || enforcer.denies(quotaRule, "", err.Error(), &warnings)) {
+			return warnings, err
+		}
+	}
+	enforcer.recordWarnings()
+	return warnings, nil
+}
+
+// newInvalidError returns a Kubernetes Invalid status error for the given object, listing every violation with its
+// field path, so that clients get all the problems of a request in a single round-trip
+func newInvalidError(kind string, name string, errs field.ErrorList) error {
+	return k8sErrors.NewInvalid(schema.GroupKind{Group: appstudiov1alpha1.GroupVersion.Group, Kind: kind}, name, errs)
+}
+
+// requestLogger returns the logger of an admission request for obj, of the given kind. Following the log conventions
+// described in docs/serviceability.md, its lines carry the controllerKind, name, namespace and resource version of the
+// object, along with the UID, user, operation and dry-run flag of the admission request when there is one in ctx.
+func requestLogger(ctx context.Context, log logr.Logger, kind string, obj client.Object) logr.Logger {
+	log = log.WithValues("controllerKind", kind, "name", obj.GetName(), "namespace", obj.GetNamespace(), "resourceVersion", obj.GetResourceVersion())
+	if req, err := admission.RequestFromContext(ctx); err == nil {
+		log = log.WithValues("admissionRequestUID", req.UID, "user", req.UserInfo.Username, "operation", req.Operation, "dryRun", isDryRun(ctx))
+	}
+	return log
+}
+
+// isDryRun returns true if ctx holds a dry-run admission request, whose object is not persisted
+func isDryRun(ctx context.Context) bool {
+	req, err := admission.RequestFromContext(ctx)
+	return err == nil && req.DryRun != nil && *req.DryRun
 }
@@ -0,0 +1,73 @@
+//
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	appstudiov1alpha1 "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestRequestLogger(t *testing.T) {
+	comp := &appstudiov1alpha1.Component{
+		ObjectMeta: v1.ObjectMeta{
+			Name:            "component1",
+			Namespace:       "default",
+			ResourceVersion: "42",
+		},
+	}
+	dryRun := true
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{
+			name: "object fields only without an admission request",
+			ctx:  context.Background(),
+			want: `"level"=0 "msg"="validating the create request" "controllerKind"="Component" "name"="component1" "namespace"="default" "resourceVersion"="42"`,
+		},
+		{
+			name: "admission request fields",
+			ctx: admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				UID:       "705ab4f5-6393-11e8-b7cc-42010a800002",
+				Operation: admissionv1.Create,
+				UserInfo:  authenticationv1.UserInfo{Username: "user1"},
+				DryRun:    &dryRun,
+			}}),
+			want: `"level"=0 "msg"="validating the create request" "controllerKind"="Component" "name"="component1" "namespace"="default" "resourceVersion"="42" "admissionRequestUID"="705ab4f5-6393-11e8-b7cc-42010a800002" "user"="user1" "operation"="CREATE" "dryRun"=true`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var lines []string
+			log := funcr.New(func(prefix, args string) {
+				lines = append(lines, args)
+			}, funcr.Options{})
+
+			requestLogger(test.ctx, log, "Component", comp).Info("validating the create request")
+			assert.Equal(t, []string{test.want}, lines)
+		})
+	}
+}